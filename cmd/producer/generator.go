@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"L0/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+// Тип сообщения, по нему считается отчёт
+const (
+	kindValid     = "valid"
+	kindInvalid   = "invalid"
+	kindDuplicate = "duplicate"
+)
+
+// Справочники для правдоподобных заказов
+var (
+	firstNames = []string{"Ivan", "Anna", "Dmitry", "Olga", "Sergey", "Maria", "Alexey", "Elena", "Pavel", "Natalia", "Test", "Daria"}
+	lastNames  = []string{"Ivanov", "Petrova", "Smirnov", "Kuznetsova", "Popov", "Sokolova", "Lebedev", "Novikova", "Testov", "Morozova"}
+	streets    = []string{"Lenina", "Pushkina", "Gagarina", "Sadovaya", "Ploshad Mira", "Naberezhnaya", "Tsentralnaya", "Shkolnaya"}
+	emailHosts = []string{"gmail.com", "mail.ru", "yandex.ru", "outlook.com"}
+
+	cities = []struct{ city, region, zip, phone string }{
+		{"Moscow", "Moscow", "101", "+7495"},
+		{"Saint Petersburg", "Leningradskaya", "190", "+7812"},
+		{"Kazan", "Tatarstan", "420", "+7843"},
+		{"Novosibirsk", "Novosibirskaya", "630", "+7383"},
+		{"Yekaterinburg", "Sverdlovskaya", "620", "+7343"},
+		{"Minsk", "Minskaya", "220", "+37517"},
+		{"Almaty", "Almaty", "050", "+7727"},
+		{"Kiryat Mozkin", "Kraiot", "263", "+9720"},
+	}
+
+	products = []struct{ brand, name string }{
+		{"Vivienne Sabo", "Mascaras"},
+		{"Nike", "Sneakers"},
+		{"Adidas", "Hoodie"},
+		{"Samsung", "Phone case"},
+		{"Xiaomi", "Power bank"},
+		{"Levi's", "Jeans"},
+		{"Lego", "Constructor"},
+		{"Bosch", "Drill"},
+		{"Tefal", "Frying pan"},
+		{"Zara", "Dress"},
+	}
+	sizes = []string{"0", "XS", "S", "M", "L", "XL", "42", "44"}
+
+	payments = []struct{ currency, locale string }{
+		{"RUB", "ru"},
+		{"USD", "en"},
+		{"EUR", "en"},
+		{"KZT", "kz"},
+		{"BYN", "ru"},
+	}
+	providers        = []string{"wbpay", "sbp", "card"}
+	banks            = []string{"alpha", "sber", "tinkoff", "vtb"}
+	deliveryServices = []string{"meest", "cdek", "boxberry", "wb"}
+)
+
+// generator создаёт сообщения одного писателя. Не потокобезопасен:
+// у каждого писателя свой генератор, общий у них только sent
+type generator struct {
+	rnd      *rand.Rand
+	scenario *Scenario
+	sent     *recentOrders
+	invalid  [][]byte // error-*.json из PRODUCER_DATA_PATH
+}
+
+// next возвращает очередное сообщение и его тип в пропорциях сценария
+func (g *generator) next() (kafka.Message, string) {
+	p := g.rnd.Float64()
+	if p < g.scenario.InvalidRatio {
+		return g.invalidMessage(), kindInvalid
+	}
+	if p < g.scenario.InvalidRatio+g.scenario.DuplicateRatio {
+		if msg, ok := g.sent.random(g.rnd); ok {
+			return msg, kindDuplicate
+		}
+	}
+
+	order := g.order()
+	data, _ := json.Marshal(order)
+	msg := kafka.Message{Key: []byte(order.OrderUID), Value: data}
+	g.sent.add(msg)
+	return msg, kindValid
+}
+
+func (g *generator) order() models.Order {
+	uid := strings.ReplaceAll(uuid.NewString(), "-", "")
+	track := "WBIL" + g.code(10)
+	first, last := pick(g.rnd, firstNames), pick(g.rnd, lastNames)
+	city := pick(g.rnd, cities)
+	pay := pick(g.rnd, payments)
+
+	items := make([]models.Item, g.scenario.itemsCount(g.rnd))
+	goodsTotal := 0
+	for i := range items {
+		product := pick(g.rnd, products)
+		price := 100 + g.rnd.IntN(9900)
+		sale := 5 * g.rnd.IntN(11)
+		items[i] = models.Item{
+			ChrtID:      1_000_000 + g.rnd.Int64N(9_000_000),
+			TrackNumber: track,
+			Price:       price,
+			Rid:         g.code(20),
+			Name:        product.name,
+			Sale:        sale,
+			Size:        pick(g.rnd, sizes),
+			TotalPrice:  price * (100 - sale) / 100,
+			NmID:        1_000_000 + g.rnd.Int64N(9_000_000),
+			Brand:       product.brand,
+			Status:      202,
+		}
+		goodsTotal += items[i].TotalPrice
+	}
+
+	deliveryCost := 100 * g.rnd.IntN(16)
+	now := time.Now().UTC()
+
+	return models.Order{
+		OrderUID:    uid,
+		TrackNumber: track,
+		Entry:       "WBIL",
+		Delivery: models.Delivery{
+			Name:    first + " " + last,
+			Phone:   fmt.Sprintf("%s%07d", city.phone, g.rnd.IntN(10_000_000)),
+			Zip:     fmt.Sprintf("%s%03d", city.zip, g.rnd.IntN(1000)),
+			City:    city.city,
+			Address: fmt.Sprintf("%s %d", pick(g.rnd, streets), 1+g.rnd.IntN(150)),
+			Region:  city.region,
+			Email:   strings.ToLower(fmt.Sprintf("%s.%s%d@%s", first, last, g.rnd.IntN(100), pick(g.rnd, emailHosts))),
+		},
+		Payment: models.Payment{
+			Transaction:  uid,
+			Currency:     pay.currency,
+			Provider:     pick(g.rnd, providers),
+			Amount:       goodsTotal + deliveryCost,
+			PaymentDt:    now.Unix(),
+			Bank:         pick(g.rnd, banks),
+			DeliveryCost: deliveryCost,
+			GoodsTotal:   goodsTotal,
+		},
+		Items:           items,
+		Locale:          pay.locale,
+		CustomerID:      "customer-" + g.code(8),
+		DeliveryService: pick(g.rnd, deliveryServices),
+		Shardkey:        fmt.Sprint(g.rnd.IntN(10)),
+		SmID:            g.rnd.IntN(100),
+		DateCreated:     now,
+		OofShard:        fmt.Sprint(1 + g.rnd.IntN(2)),
+	}
+}
+
+// invalidMessage портит случайный заказ одним из способов, которые консьюмер
+// должен отправить в DLQ, или берёт готовый файл из PRODUCER_DATA_PATH
+func (g *generator) invalidMessage() kafka.Message {
+	order := g.order()
+	key := []byte(order.OrderUID)
+
+	switch g.rnd.IntN(7) {
+	case 0:
+		order.Items = nil
+	case 1:
+		order.OrderUID = ""
+	case 2:
+		order.Payment.Amount = -order.Payment.Amount
+	case 3:
+		order.Payment.Transaction = "tx-" + g.code(10)
+	case 4:
+		order.Items[0].TrackNumber = "WBIL" + g.code(10)
+	case 5:
+		data, _ := json.Marshal(order)
+		return kafka.Message{Key: key, Value: data[:len(data)/2]} // обрезанный JSON
+	default:
+		if len(g.invalid) > 0 {
+			return kafka.Message{Key: key, Value: pick(g.rnd, g.invalid)}
+		}
+		order.Delivery.Name = ""
+	}
+
+	data, _ := json.Marshal(order)
+	return kafka.Message{Key: key, Value: data}
+}
+
+// code - случайная строка из заглавных букв и цифр
+func (g *generator) code(n int) string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rnd.IntN(len(alphabet))]
+	}
+	return string(b)
+}
+
+func pick[T any](rnd *rand.Rand, values []T) T {
+	return values[rnd.IntN(len(values))]
+}
+
+// recentOrders - последние отправленные валидные заказы, из них берутся дубликаты
+type recentOrders struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+	next int
+}
+
+const recentOrdersSize = 1000
+
+func (r *recentOrders) add(msg kafka.Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.msgs) < recentOrdersSize {
+		r.msgs = append(r.msgs, msg)
+		return
+	}
+	r.msgs[r.next] = msg
+	r.next = (r.next + 1) % recentOrdersSize
+}
+
+func (r *recentOrders) random(rnd *rand.Rand) (kafka.Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.msgs) == 0 {
+		return kafka.Message{}, false
+	}
+	return r.msgs[rnd.IntN(len(r.msgs))], true
+}
+
+// loadInvalidData читает error-*.json. Файлы необязательны: невалидные
+// сообщения генерируются и без них
+func loadInvalidData(dataPath string) ([][]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dataPath, "error-*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var data [][]byte
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, content)
+	}
+	return data, nil
+}
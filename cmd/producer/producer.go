@@ -2,138 +2,97 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
-	"math/rand"
 	"os"
-	"path/filepath"
-	"runtime"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"L0/internal/config"
+	"L0/internal/tracing"
 
-	"github.com/cenkalti/backoff/v4"
-	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
 )
 
 func main() {
-	
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})))
+	logLevel := &slog.LevelVar{}
+	slog.SetDefault(slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: logLevel,
+	}))))
 
 	cfg := config.MustLoad()
 
-	runtime.GOMAXPROCS(1)
+	level, _ := config.ParseLogLevel(cfg.LogLevel) // уже проверен в config.Validate
+	logLevel.Set(level)
 
-	validData, invalidData, err := loadTestData(cfg.Producer.DataPath)
+	// Трасса каждого заказа начинается здесь и продолжается в консьюмере через заголовки сообщения
+	tracingCfg := cfg.Tracing
+	tracingCfg.ServiceName += "-producer"
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingCfg)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	scenarios, err := loadScenarios(cfg.Producer)
+	if err != nil {
+		slog.Error("Failed to load scenarios", "error", err)
+		os.Exit(1)
+	}
+
+	invalidData, err := loadInvalidData(cfg.Producer.DataPath)
 	if err != nil {
 		slog.Error("Failed to load test data", "error", err)
 		os.Exit(1)
 	}
 
+	// Сообщения нескольких писателей собираются в общие пачки
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.Kafka.Brokers...),
 		Topic:        cfg.Kafka.Topic,
 		Balancer:     &kafka.Hash{},
 		RequiredAcks: kafka.RequireAll,
+		BatchTimeout: 10 * time.Millisecond,
 	}
-	defer writer.Close()
-
-	slog.Info("Producer started. Sending messages...", "delay", cfg.Producer.Delay)
-
-	ticker := time.NewTicker(cfg.Producer.Delay)
-	defer ticker.Stop()
-
-	messageCount := 0
-
-	for range ticker.C {
-		var msg kafka.Message
-		var msgType string
-
-		messageCount++
-
-		if messageCount%3 != 0 {
-			msgType = "VALID"
-			orderData, orderUID := generateValidOrder(validData)
-			msg = kafka.Message{
-				Key:   []byte(orderUID),
-				Value: orderData,
-			}
-		} else {
-			msgType = "INVALID"
-			invalidMsg := invalidData[rand.Intn(len(invalidData))]
-			msg = kafka.Message{
-				Key:   []byte(uuid.NewString()),
-				Value: invalidMsg,
-			}
-		}
 
-		// Retry отправки сообщения с backoff
-		sendOperation := func() error {
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			return writer.WriteMessages(ctx, msg)
-		}
+	r := &runner{
+		writer:  writer,
+		topic:   cfg.Kafka.Topic,
+		tracer:  otel.Tracer("L0/cmd/producer"),
+		invalid: invalidData,
+		sent:    &recentOrders{},
+	}
 
-		bo := backoff.NewExponentialBackOff()
-		bo.MaxElapsedTime = 15 * time.Second
-		bo.InitialInterval = 500 * time.Millisecond
-		bo.MaxInterval = 3 * time.Second
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
 
-		err := backoff.Retry(sendOperation, bo)
-		if err != nil {
-			slog.Error("Failed to send message after retries", "type", msgType, "error", err)
-		} else {
-			slog.Info("Sent message", "type", msgType)
+	var reports []*stats
+	for _, sc := range scenarios {
+		if ctx.Err() != nil {
+			break
 		}
-	}
-}
+		slog.Info("Starting scenario", "scenario", sc.Name, "rate", sc.rateString(), "duration", sc.Duration,
+			"writers", sc.Writers, "invalid_ratio", sc.InvalidRatio, "duplicate_ratio", sc.DuplicateRatio)
 
-func loadTestData(dataPath string) (map[string]interface{}, [][]byte, error) {
-	validPath := filepath.Join(dataPath, "valid-order-template.json")
-	validFile, err := os.ReadFile(validPath)
-	if err != nil {
-		return nil, nil, err
-	}
+		st := r.run(ctx, sc)
+		reports = append(reports, st)
 
-	var validData map[string]interface{}
-	if err := json.Unmarshal(validFile, &validData); err != nil {
-		return nil, nil, err
+		slog.Info("Scenario finished", "scenario", sc.Name, "sent", st.total(), "failed", st.failed.Load(),
+			"rate", st.throughput(st.elapsed))
 	}
 
-	var invalidData [][]byte
-	invalidFiles, err := filepath.Glob(filepath.Join(dataPath, "error-*.json"))
-	if err != nil {
-		return nil, nil, err
-	}
+	printReport(os.Stdout, reports)
 
-	for _, file := range invalidFiles {
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return nil, nil, err
-		}
-		invalidData = append(invalidData, data)
+	if err := writer.Close(); err != nil {
+		slog.Error("Failed to close writer", "error", err)
 	}
-
-	return validData, invalidData, nil
-}
-
-func generateValidOrder(template map[string]interface{}) ([]byte, string) {
-	orderData := make(map[string]interface{})
-	for k, v := range template {
-		orderData[k] = v
+	if err := shutdownTracing(context.Background()); err != nil {
+		slog.Error("Tracing shutdown failed", "error", err)
 	}
 
-	newUID := uuid.NewString()
-	orderData["order_uid"] = newUID
-	orderData["date_created"] = time.Now().UTC().Format(time.RFC3339)
-
-	if payment, ok := orderData["payment"].(map[string]interface{}); ok {
-		payment["transaction"] = newUID
+	// Ненулевой код, чтобы прогон с потерянными сообщениями было видно в CI
+	if mergeStats(reports).failed.Load() > 0 {
+		os.Exit(1)
 	}
-
-	result, _ := json.Marshal(orderData)
-	return result, newUID
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// maxReportErrors - сколько самых частых ошибок показать по каждому этапу
+const maxReportErrors = 5
+
+// stats - итоги одного этапа
+type stats struct {
+	name    string
+	target  string
+	started time.Time
+	elapsed time.Duration
+
+	valid, invalid, duplicate, failed atomic.Int64
+
+	mu        sync.Mutex
+	latencies []time.Duration // время отправки успешных сообщений, с учётом повторов
+	errors    map[string]int
+}
+
+func newStats(sc Scenario) *stats {
+	return &stats{
+		name:    sc.Name,
+		target:  sc.rateString(),
+		started: time.Now(),
+		errors:  make(map[string]int),
+	}
+}
+
+func (s *stats) record(kind string, latency time.Duration, err error) {
+	if err != nil {
+		s.failed.Add(1)
+		s.mu.Lock()
+		s.errors[err.Error()]++
+		s.mu.Unlock()
+		return
+	}
+
+	switch kind {
+	case kindValid:
+		s.valid.Add(1)
+	case kindInvalid:
+		s.invalid.Add(1)
+	case kindDuplicate:
+		s.duplicate.Add(1)
+	}
+	s.mu.Lock()
+	s.latencies = append(s.latencies, latency)
+	s.mu.Unlock()
+}
+
+// total - сколько сообщений отправлено успешно
+func (s *stats) total() int64 {
+	return s.valid.Load() + s.invalid.Load() + s.duplicate.Load()
+}
+
+func (s *stats) throughput(elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.total()) / elapsed.Seconds()
+}
+
+func (s *stats) finish() {
+	s.elapsed = time.Since(s.started)
+	s.mu.Lock()
+	slices.Sort(s.latencies)
+	s.mu.Unlock()
+}
+
+// percentile ожидает отсортированные latencies, то есть вызов после finish
+func (s *stats) percentile(p float64) time.Duration {
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	return s.latencies[int(p*float64(len(s.latencies)-1))]
+}
+
+// mergeStats складывает этапы в общий итог
+func mergeStats(reports []*stats) *stats {
+	total := &stats{name: "TOTAL", target: "-", errors: make(map[string]int)}
+	for _, s := range reports {
+		total.elapsed += s.elapsed
+		total.valid.Add(s.valid.Load())
+		total.invalid.Add(s.invalid.Load())
+		total.duplicate.Add(s.duplicate.Load())
+		total.failed.Add(s.failed.Load())
+		total.latencies = append(total.latencies, s.latencies...)
+		for err, n := range s.errors {
+			total.errors[err] += n
+		}
+	}
+	slices.Sort(total.latencies)
+	return total
+}
+
+// printReport печатает таблицу по этапам и самые частые ошибки
+func printReport(w io.Writer, reports []*stats) {
+	rows := reports
+	if len(reports) > 1 {
+		rows = append(slices.Clone(reports), mergeStats(reports))
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "SCENARIO\tTARGET\tDURATION\tSENT\tVALID\tINVALID\tDUPLICATE\tFAILED\tRATE/S\tP50\tP95\tP99\tMAX\t")
+	for _, s := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+			s.name, s.target, s.elapsed.Round(time.Millisecond),
+			s.total(), s.valid.Load(), s.invalid.Load(), s.duplicate.Load(), s.failed.Load(),
+			s.throughput(s.elapsed),
+			s.percentile(0.5).Round(time.Microsecond), s.percentile(0.95).Round(time.Microsecond),
+			s.percentile(0.99).Round(time.Microsecond), s.percentile(1).Round(time.Microsecond),
+		)
+	}
+	tw.Flush()
+
+	for _, s := range reports {
+		if len(s.errors) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\nErrors in %s:\n", s.name)
+		for _, e := range topErrors(s.errors, maxReportErrors) {
+			fmt.Fprintf(w, "  %6d  %s\n", e.count, e.text)
+		}
+	}
+}
+
+type errorCount struct {
+	text  string
+	count int
+}
+
+func topErrors(errors map[string]int, limit int) []errorCount {
+	list := make([]errorCount, 0, len(errors))
+	for text, count := range errors {
+		list = append(list, errorCount{text: text, count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].count != list[j].count {
+			return list[i].count > list[j].count
+		}
+		return list[i].text < list[j].text
+	})
+	return list[:min(limit, len(list))]
+}
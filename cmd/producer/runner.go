@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"L0/internal/tracing"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+// progressInterval - как часто писать в лог промежуточные итоги этапа
+const progressInterval = 5 * time.Second
+
+// drainTimeout - сколько после SIGINT/SIGTERM ждать отправки, начатые до него
+const drainTimeout = 5 * time.Second
+
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// runner выполняет сценарии на общем writer'е
+type runner struct {
+	writer  messageWriter
+	topic   string
+	tracer  trace.Tracer
+	invalid [][]byte
+	sent    *recentOrders // дубликаты могут повторять заказы из прошлых этапов
+}
+
+// run держит частоту сценария, пока не истечёт Duration или не отменят ctx.
+// Отправка, начатая до конца этапа, дожидается результата. После отмены ctx
+// она дописывается ещё drainTimeout, чтобы остановка не считалась ошибкой
+func (r *runner) run(ctx context.Context, sc Scenario) *stats {
+	st := newStats(sc)
+
+	sendCtx, cancelSend := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelSend()
+	stopDrain := context.AfterFunc(ctx, func() { time.AfterFunc(drainTimeout, cancelSend) })
+	defer stopDrain()
+
+	runCtx := ctx
+	if sc.Duration > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, sc.Duration)
+		defer cancel()
+	}
+
+	limiter := newRateLimiter(sc)
+
+	var wg sync.WaitGroup
+	for range sc.Writers {
+		gen := &generator{
+			rnd:      rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+			scenario: &sc,
+			sent:     r.sent,
+			invalid:  r.invalid,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for limiter.Wait(runCtx) == nil {
+				msg, kind := gen.next()
+				r.send(sendCtx, msg, kind, st)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			slog.Info("Scenario progress", "scenario", sc.Name, "sent", st.total(), "failed", st.failed.Load(),
+				"rate", st.throughput(time.Since(st.started)))
+		case <-done:
+			st.finish()
+			return st
+		}
+	}
+}
+
+// send отправляет одно сообщение с retry/backoff, как раньше, и учитывает результат
+func (r *runner) send(ctx context.Context, msg kafka.Message, kind string, st *stats) {
+	spanCtx, span := r.tracer.Start(ctx, "send "+r.topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(r.topic),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+			attribute.String("message.type", kind),
+		),
+	)
+	defer span.End()
+	tracing.InjectKafka(spanCtx, &msg)
+
+	sendOperation := func() error {
+		ctx, cancel := context.WithTimeout(spanCtx, 5*time.Second)
+		defer cancel()
+		return r.writer.WriteMessages(ctx, msg)
+	}
+
+	bo := backoff.NewExponentialBackOff()
+	bo.MaxElapsedTime = 15 * time.Second
+	bo.InitialInterval = 500 * time.Millisecond
+	bo.MaxInterval = 3 * time.Second
+
+	start := time.Now()
+	err := backoff.Retry(sendOperation, backoff.WithContext(bo, spanCtx))
+	st.record(kind, time.Since(start), err)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		slog.ErrorContext(spanCtx, "Failed to send message after retries", "type", kind, "error", err)
+		return
+	}
+	slog.DebugContext(spanCtx, "Sent message", "type", kind)
+}
+
+// newRateLimiter - общий лимит сценария на всех писателей
+func newRateLimiter(sc Scenario) *rate.Limiter {
+	if sc.Rate == 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(sc.Rate), sc.Writers)
+}
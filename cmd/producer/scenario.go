@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"L0/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario - один этап нагрузки. Этапы из файла выполняются по очереди
+type Scenario struct {
+	Name           string        `yaml:"name"`
+	Rate           float64       `yaml:"rate"`            // сообщений в секунду на всех писателей, 0 - без ограничения
+	Duration       time.Duration `yaml:"duration"`        // 0 - до SIGINT/SIGTERM, допустимо только у последнего этапа
+	Writers        int           `yaml:"writers"`         // сколько горутин пишут в Kafka одновременно
+	InvalidRatio   float64       `yaml:"invalid_ratio"`   // доля невалидных сообщений
+	DuplicateRatio float64       `yaml:"duplicate_ratio"` // доля повторов уже отправленных заказов
+	Items          []ItemsBucket `yaml:"items"`           // распределение количества товаров в заказе
+}
+
+// ItemsBucket - с весом Weight в заказе от Min до Max товаров
+type ItemsBucket struct {
+	Min    int `yaml:"min"`
+	Max    int `yaml:"max"` // 0 - ровно Min
+	Weight int `yaml:"weight"`
+}
+
+type scenarioFile struct {
+	Scenarios []Scenario `yaml:"scenarios"`
+}
+
+// loadScenarios читает сценарии из PRODUCER_SCENARIO_FILE. Без файла
+// producer работает как раньше: одно сообщение раз в PRODUCER_DELAY,
+// каждое третье невалидное, пока его не остановят
+func loadScenarios(cfg config.Producer) ([]Scenario, error) {
+	if cfg.ScenarioFile == "" {
+		return []Scenario{{
+			Name:         "default",
+			Rate:         1 / cfg.Delay.Seconds(),
+			Writers:      1,
+			InvalidRatio: 1.0 / 3,
+			Items:        []ItemsBucket{{Min: 1, Max: 3, Weight: 1}},
+		}}, nil
+	}
+
+	data, err := os.ReadFile(cfg.ScenarioFile)
+	if err != nil {
+		return nil, fmt.Errorf("read scenario file: %w", err)
+	}
+	return parseScenarios(data)
+}
+
+func parseScenarios(data []byte) ([]Scenario, error) {
+	var file scenarioFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true) // опечатка в ключе не должна молча менять нагрузку
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse scenario file: %w", err)
+	}
+	if len(file.Scenarios) == 0 {
+		return nil, errors.New("scenario file has no scenarios")
+	}
+
+	var errs []error
+	for i := range file.Scenarios {
+		sc := &file.Scenarios[i]
+		sc.setDefaults(i)
+		if err := sc.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("scenario %q: %w", sc.Name, err))
+		}
+		if sc.Duration == 0 && i < len(file.Scenarios)-1 {
+			errs = append(errs, fmt.Errorf("scenario %q: only the last scenario may run without duration", sc.Name))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return file.Scenarios, nil
+}
+
+func (sc *Scenario) setDefaults(i int) {
+	if sc.Name == "" {
+		sc.Name = fmt.Sprintf("scenario-%d", i+1)
+	}
+	if sc.Writers == 0 {
+		sc.Writers = 1
+	}
+	if len(sc.Items) == 0 {
+		sc.Items = []ItemsBucket{{Min: 1, Weight: 1}}
+	}
+	for j := range sc.Items {
+		if sc.Items[j].Max == 0 {
+			sc.Items[j].Max = sc.Items[j].Min
+		}
+	}
+}
+
+func (sc *Scenario) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(sc.Rate >= 0, "rate must not be negative, got %v", sc.Rate)
+	check(sc.Duration >= 0, "duration must not be negative, got %s", sc.Duration)
+	check(sc.Writers >= 1, "writers must be at least 1, got %d", sc.Writers)
+	check(sc.InvalidRatio >= 0 && sc.InvalidRatio <= 1, "invalid_ratio must be between 0 and 1, got %v", sc.InvalidRatio)
+	check(sc.DuplicateRatio >= 0 && sc.DuplicateRatio <= 1, "duplicate_ratio must be between 0 and 1, got %v", sc.DuplicateRatio)
+	check(sc.InvalidRatio+sc.DuplicateRatio <= 1, "invalid_ratio + duplicate_ratio must not exceed 1")
+	for j, b := range sc.Items {
+		check(b.Min >= 1, "items[%d]: min must be at least 1, got %d", j, b.Min)
+		check(b.Max >= b.Min, "items[%d]: max must not be less than min", j)
+		check(b.Weight > 0, "items[%d]: weight must be positive, got %d", j, b.Weight)
+	}
+
+	return errors.Join(errs...)
+}
+
+// itemsCount выбирает корзину по весу, а количество товаров в ней - равномерно
+func (sc *Scenario) itemsCount(rnd *rand.Rand) int {
+	total := 0
+	for _, b := range sc.Items {
+		total += b.Weight
+	}
+
+	n := rnd.IntN(total)
+	for _, b := range sc.Items {
+		if n < b.Weight {
+			return b.Min + rnd.IntN(b.Max-b.Min+1)
+		}
+		n -= b.Weight
+	}
+	return sc.Items[len(sc.Items)-1].Min
+}
+
+// rateString - целевая частота для отчёта
+func (sc *Scenario) rateString() string {
+	if sc.Rate == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.1f/s", sc.Rate)
+}
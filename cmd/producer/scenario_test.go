@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"testing"
+	"time"
+
+	"L0/internal/models"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestParseScenarios(t *testing.T) {
+	scenarios, err := parseScenarios([]byte(`
+scenarios:
+  - name: warmup
+    rate: 50
+    duration: 30s
+  - rate: 500
+    duration: 0s
+    writers: 8
+    invalid_ratio: 0.1
+    duplicate_ratio: 0.05
+    items:
+      - {min: 1, weight: 70}
+      - {min: 2, max: 10, weight: 30}
+`))
+	require.NoError(t, err)
+	require.Len(t, scenarios, 2)
+
+	assert.Equal(t, "warmup", scenarios[0].Name)
+	assert.Equal(t, 30*time.Second, scenarios[0].Duration)
+	assert.Equal(t, 1, scenarios[0].Writers, "default writers")
+	assert.Equal(t, []ItemsBucket{{Min: 1, Max: 1, Weight: 1}}, scenarios[0].Items, "default items")
+
+	assert.Equal(t, "scenario-2", scenarios[1].Name)
+	assert.Equal(t, 8, scenarios[1].Writers)
+	assert.Equal(t, []ItemsBucket{{Min: 1, Max: 1, Weight: 70}, {Min: 2, Max: 10, Weight: 30}}, scenarios[1].Items)
+}
+
+func TestParseScenarios_Errors(t *testing.T) {
+	_, err := parseScenarios([]byte(`
+scenarios:
+  - name: endless
+    rate: -1
+    invalid_ratio: 0.8
+    duplicate_ratio: 0.5
+    items: [{min: 0, weight: 1}]
+  - name: last
+    duration: 1m
+`))
+	require.Error(t, err)
+	for _, want := range []string{
+		"rate must not be negative",
+		"invalid_ratio + duplicate_ratio must not exceed 1",
+		"items[0]: min must be at least 1",
+		`scenario "endless": only the last scenario may run without duration`,
+	} {
+		assert.Contains(t, err.Error(), want)
+	}
+
+	_, err = parseScenarios([]byte("scenarios:\n  - rps: 10\n"))
+	assert.ErrorContains(t, err, "field rps not found")
+}
+
+func newTestGenerator(sc *Scenario) *generator {
+	return &generator{rnd: rand.New(rand.NewPCG(1, 2)), scenario: sc, sent: &recentOrders{}}
+}
+
+func TestGenerator_ValidOrdersPassValidation(t *testing.T) {
+	sc := &Scenario{Items: []ItemsBucket{{Min: 2, Max: 4, Weight: 1}}}
+	gen := newTestGenerator(sc)
+
+	for range 100 {
+		msg, kind := gen.next()
+		require.Equal(t, kindValid, kind)
+
+		order, err := models.DecodeOrder(msg.Value)
+		require.NoError(t, err)
+		assert.Equal(t, order.OrderUID, string(msg.Key))
+		assert.True(t, len(order.Items) >= 2 && len(order.Items) <= 4)
+		assert.Equal(t, order.Payment.GoodsTotal+order.Payment.DeliveryCost, order.Payment.Amount)
+	}
+}
+
+func TestGenerator_InvalidAndDuplicateMessages(t *testing.T) {
+	gen := newTestGenerator(&Scenario{InvalidRatio: 1, Items: []ItemsBucket{{Min: 1, Max: 1, Weight: 1}}})
+	for range 50 {
+		msg, kind := gen.next()
+		require.Equal(t, kindInvalid, kind)
+		_, err := models.DecodeOrder(msg.Value)
+		assert.Error(t, err)
+	}
+
+	gen.scenario = &Scenario{DuplicateRatio: 1, Items: []ItemsBucket{{Min: 1, Max: 1, Weight: 1}}}
+	first, kind := gen.next()
+	require.Equal(t, kindValid, kind, "nothing to duplicate yet")
+
+	dup, kind := gen.next()
+	assert.Equal(t, kindDuplicate, kind)
+	assert.Equal(t, first.Value, dup.Value)
+}
+
+// countingWriter запоминает ключи, первые failures вызовов отказывают
+type countingWriter struct {
+	mu       sync.Mutex
+	keys     []string
+	failures int
+}
+
+func (w *countingWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.failures > 0 {
+		w.failures--
+		return errors.New("broker is unavailable")
+	}
+	for _, msg := range msgs {
+		w.keys = append(w.keys, string(msg.Key))
+	}
+	return nil
+}
+
+// slowWriter отвечает через delay, если контекст не отменят раньше
+type slowWriter struct {
+	countingWriter
+	delay time.Duration
+}
+
+func (w *slowWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	select {
+	case <-time.After(w.delay):
+		return w.countingWriter.WriteMessages(ctx, msgs...)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestRunner_RunDrainsInFlightSendsOnInterrupt(t *testing.T) {
+	writer := &slowWriter{delay: 50 * time.Millisecond}
+	r := &runner{writer: writer, topic: "orders", tracer: noop.NewTracerProvider().Tracer(""), sent: &recentOrders{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Millisecond)
+	defer cancel()
+
+	// Этап без Duration, как сценарий по умолчанию: его останавливает только сигнал
+	st := r.run(ctx, Scenario{Name: "default", Writers: 4, Items: []ItemsBucket{{Min: 1, Max: 1, Weight: 1}}})
+
+	assert.Zero(t, st.failed.Load(), "interrupted sends are not failures")
+	assert.Positive(t, st.total())
+	assert.EqualValues(t, len(writer.keys), st.total())
+}
+
+func TestRunner_RunReportsThroughput(t *testing.T) {
+	writer := &countingWriter{failures: 1}
+	r := &runner{writer: writer, topic: "orders", tracer: noop.NewTracerProvider().Tracer(""), sent: &recentOrders{}}
+
+	st := r.run(context.Background(), Scenario{
+		Name:           "burst",
+		Rate:           200,
+		Duration:       300 * time.Millisecond,
+		Writers:        4,
+		InvalidRatio:   0.2,
+		DuplicateRatio: 0.2,
+		Items:          []ItemsBucket{{Min: 1, Max: 3, Weight: 1}},
+	})
+
+	assert.Zero(t, st.failed.Load(), "failed write is retried")
+	assert.EqualValues(t, len(writer.keys), st.total())
+	assert.InDelta(t, 60, st.total(), 20, "about rate * duration messages")
+	assert.Positive(t, st.valid.Load())
+	assert.Positive(t, st.invalid.Load())
+	assert.Positive(t, st.duplicate.Load())
+
+	var out bytes.Buffer
+	printReport(&out, []*stats{st})
+	assert.Contains(t, out.String(), "burst")
+	assert.Contains(t, out.String(), "200.0/s")
+}
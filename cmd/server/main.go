@@ -3,6 +3,7 @@ package main
 import (
     "context"
     "log/slog"
+    "net"
     "net/http"
     _ "net/http/pprof" // Импорт для pprof
     "os"
@@ -12,13 +13,16 @@ import (
     "time"
 
     "L0/internal/config"
-    "L0/internal/repository/postgres"
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+    "L0/internal/pii"
+    "L0/internal/repository"
     "L0/internal/service"
+    "L0/internal/tracing"
+    tGRPC "L0/internal/transport/grpc"
     tHTTP "L0/internal/transport/http"
     "L0/internal/transport/kafka"
     _ "L0/docs" // Импорт для swagger
-
-    "github.com/jackc/pgx/v5/pgxpool"
 )
 
 // @title Order API
@@ -35,38 +39,116 @@ import (
 
 // @host localhost:8081
 // @BasePath /
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API-ключ (AUTH_KEYS или таблица api_keys). Также принимается Authorization: Bearer <key>
 func main() {
-    // Json логи по умолчанию
-    slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-        Level: slog.LevelInfo,
-    })))
+    // Json логи по умолчанию, с trace_id/span_id для записей с контекстом.
+    // Уровень задаётся LOG_LEVEL и меняется по SIGHUP
+    logLevel := &slog.LevelVar{}
+    slog.SetDefault(slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+        Level: logLevel,
+    }))))
 
     cfg := config.MustLoad()
 
-    pool, err := pgxpool.New(context.Background(), cfg.DBDSN)
+    level, _ := config.ParseLogLevel(cfg.LogLevel) // уже проверен в config.Validate
+    logLevel.Set(level)
+
+    shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
     if err != nil {
-        slog.Error("Failed to connect to database", "error", err)
+        slog.Error("Failed to set up tracing", "error", err)
         os.Exit(1)
     }
-    defer pool.Close()
 
-    if err := pool.Ping(context.Background()); err != nil {
-        slog.Error("Failed to ping database", "error", err)
+    store, err := openStorage(context.Background(), cfg)
+    if err != nil {
+        slog.Error("Failed to open storage", "engine", cfg.Storage.Engine, "error", err)
         os.Exit(1)
     }
+    defer store.close()
+
+    // Подкоманда: server migrate <up|down [N]|status>
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(context.Background(), store.migrator, os.Args[2:]); err != nil {
+            slog.Error("Migration command failed", "error", err)
+            store.close()
+            os.Exit(1)
+        }
+        return
+    }
 
-    slog.Info("Connected to database")
+    // Подкоманда: server pii rewrap [BATCH_SIZE]
+    if len(os.Args) > 1 && os.Args[1] == "pii" {
+        if err := runPII(context.Background(), store, os.Args[2:]); err != nil {
+            slog.Error("PII command failed", "error", err)
+            store.close()
+            os.Exit(1)
+        }
+        return
+    }
 
-    repo := postgres.New(pool, cfg)
-    orderService := service.NewOrderService(repo, cfg)
+    if store.migrator != nil && (cfg.DB.MigrateOnStart || store.autoMigrate) {
+        applied, err := store.migrator.Up(context.Background())
+        if err != nil {
+            slog.Error("Failed to apply migrations", "error", err)
+            os.Exit(1)
+        }
+        slog.Info("Migrations are up to date", "applied", applied)
+    }
+
+    orderService := service.NewOrderService(store.repo, cfg)
 
     consumer := kafka.NewConsumer(orderService, cfg)
-    orderHandler, err := tHTTP.NewOrderHandler(orderService, "web/template/order.html")
+    masker, err := pii.NewMasker(cfg.PII.MaskRules, cfg.PII.UnmaskedRole)
+    if err != nil {
+        slog.Error("Invalid PII masking rules", "error", err)
+        os.Exit(1)
+    }
+
+    orderHandler, err := tHTTP.NewOrderHandler(orderService, "web/template", masker)
 	if err != nil {
     	slog.Error("Failed to create order handler", "error", err)
     	os.Exit(1)
 	}
-    router := tHTTP.NewRouter(orderHandler, nil, cfg.RateLimiter.RPS, cfg.RateLimiter.Burst, cfg.RateLimiter.Enabled)
+
+    checks := append(store.checks, tHTTP.HealthCheck{Name: "kafka", Check: consumer.HealthCheck})
+    healthHandler := tHTTP.NewHealthHandler(cfg.Health.CheckTimeout, checks...)
+
+    var limiter *mw.RateLimiter
+    if cfg.RateLimiter.Enabled {
+        limiter, err = mw.NewRateLimiter(cfg.RateLimiter)
+        if err != nil {
+            slog.Error("Failed to create rate limiter", "error", err)
+            os.Exit(1)
+        }
+    }
+
+    var auth *mw.Authenticator
+    if cfg.Auth.Enabled {
+        var keyStore repository.APIKeyRepository
+        if cfg.Auth.KeysFromDB {
+            if store.apiKeys == nil {
+                slog.Error("AUTH_KEYS_FROM_DB is not supported by storage engine", "engine", cfg.Storage.Engine)
+                os.Exit(1)
+            }
+            keyStore = store.apiKeys
+        }
+
+        auth, err = mw.NewAuthenticator(cfg.Auth, keyStore)
+        if err != nil {
+            slog.Error("Failed to create authenticator", "error", err)
+            os.Exit(1)
+        }
+    }
+
+    router := tHTTP.NewRouter(orderHandler, healthHandler, tHTTP.RouterOptions{
+        Limiter:        limiter,
+        Auth:           auth,
+        ProtectSwagger: cfg.Auth.ProtectSwagger,
+    })
 
     server := &http.Server{
         Addr:         cfg.HTTPAddr,
@@ -75,12 +157,19 @@ func main() {
         WriteTimeout: cfg.HTTPServer.WriteTimeout,
     }
 
+    // SSE соединения никогда не становятся idle, без этого Shutdown ждал бы до таймаута
+    server.RegisterOnShutdown(orderHandler.CloseStreams)
+
     // Отдельный сервер для pprof
     var pprofServer *http.Server
     if cfg.Monitor.PprofEnabled {
+        var pprofHandler http.Handler = http.DefaultServeMux // pprof регистрируется в DefaultServeMux
+        if auth != nil && cfg.Auth.ProtectPprof {
+            pprofHandler = auth.Handler(mw.RequireRole(models.RoleAdmin)(pprofHandler))
+        }
         pprofServer = &http.Server{
             Addr:    cfg.Monitor.PprofAddr,
-            Handler: http.DefaultServeMux, // pprof регистрируется в DefaultServeMux
+            Handler: pprofHandler,
         }
     }
 
@@ -88,7 +177,14 @@ func main() {
     defer cancel()
 
     go monitorGoroutines(ctx, cfg.Monitor.GoroutinesInterval)
-    go consumer.Run(ctx)
+
+    if limiter != nil {
+        go limiter.Run(ctx)
+    }
+
+    // kill -HUP перечитывает конфигурацию
+    reload := &reloader{current: cfg, logLevel: logLevel, limiter: limiter, orders: orderService}
+    go reload.Run(ctx)
 
     // Запускаем pprof сервер
     if cfg.Monitor.PprofEnabled {
@@ -100,7 +196,7 @@ func main() {
         }()
     }
 
-    // Запускаем основной HTTP сервер
+    // Запускаем основной HTTP сервер. До окончания стартовых задач /readyz отвечает 503
     go func() {
         slog.Info("Starting HTTP server", "addr", cfg.HTTPAddr)
         if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -108,8 +204,43 @@ func main() {
         }
     }()
 
+    // gRPC сервер на отдельном порту
+    var grpcServer *tGRPC.Server
+    if cfg.GRPC.Enabled {
+        lis, err := net.Listen("tcp", cfg.GRPC.Addr)
+        if err != nil {
+            slog.Error("Failed to listen for gRPC", "addr", cfg.GRPC.Addr, "error", err)
+            os.Exit(1)
+        }
+
+        grpcServer = tGRPC.NewServer(orderService, tGRPC.Options{Auth: auth, Masker: masker})
+        go func() {
+            slog.Info("Starting gRPC server", "addr", cfg.GRPC.Addr)
+            if err := grpcServer.Serve(lis); err != nil {
+                slog.Error("gRPC server failed", "error", err)
+            }
+        }()
+    }
+
+    // Прогреваем кэш до того, как сервис сообщит о готовности, чтобы первые запросы не шли в БД
+    if err := orderService.WarmUpCache(ctx); err != nil {
+        slog.Error("Failed to warm up cache", "error", err)
+    }
+
+    go consumer.Run(ctx)
+
+    var relay *kafka.OutboxRelay
+    if cfg.Outbox.Enabled {
+        relay = kafka.NewOutboxRelay(store.outbox, cfg)
+        go relay.Run(ctx)
+    }
+
+    healthHandler.SetReady()
+    slog.Info("Service is ready")
+
     <-ctx.Done()
     slog.Info("Shutting down...")
+    healthHandler.SetStopping()
 
     shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
     defer shutdownCancel()
@@ -119,6 +250,13 @@ func main() {
         slog.Error("HTTP server shutdown failed", "error", err)
     }
 
+    // Останавливаем gRPC сервер: открытые WatchOrders завершаются сразу
+    if grpcServer != nil {
+        if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+            slog.Error("gRPC server shutdown failed", "error", err)
+        }
+    }
+
     // Останавливаем pprof сервер
     if cfg.Monitor.PprofEnabled && pprofServer != nil {
         if err := pprofServer.Shutdown(shutdownCtx); err != nil {
@@ -127,6 +265,14 @@ func main() {
     }
 
     consumer.Close()
+    if relay != nil {
+        relay.Close()
+    }
+
+    // Дописываем span'ы, накопленные к моменту остановки
+    if err := shutdownTracing(shutdownCtx); err != nil {
+        slog.Error("Tracing shutdown failed", "error", err)
+    }
     slog.Info("Shutdown complete")
 }
 
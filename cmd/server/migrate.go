@@ -0,0 +1,70 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+
+    "L0/internal/migrations"
+)
+
+const migrateUsage = "usage: server migrate <up|down [N]|status>"
+
+// runMigrate выполняет подкоманду migrate: up, down [N] (по умолчанию 1), status
+func runMigrate(ctx context.Context, migrator *migrations.Migrator, args []string) error {
+    if len(args) == 0 {
+        return errors.New(migrateUsage)
+    }
+    if migrator == nil {
+        return errors.New("storage engine has no migrations")
+    }
+
+    var err error
+
+    switch args[0] {
+    case "up":
+        n, err := migrator.Up(ctx)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("applied %d migration(s)\n", n)
+
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            steps, err = strconv.Atoi(args[1])
+            if err != nil || steps < 1 {
+                return fmt.Errorf("invalid number of steps %q", args[1])
+            }
+        }
+        n, err := migrator.Down(ctx, steps)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("reverted %d migration(s)\n", n)
+
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+        for _, s := range statuses {
+            appliedAt := "pending"
+            if s.Applied {
+                appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+            }
+            fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+        }
+        return tw.Flush()
+
+    default:
+        return errors.New(migrateUsage)
+    }
+
+    return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strconv"
+)
+
+const piiUsage = "usage: server pii rewrap [BATCH_SIZE]"
+
+const defaultRewrapBatch = 500
+
+// runPII выполняет подкоманду pii. rewrap перешифровывает строки основным
+// ключом из PII_ENCRYPTION_KEY_FILE: после ротации ключа и после включения
+// шифрования на БД с уже сохранёнными заказами
+func runPII(ctx context.Context, store *storage, args []string) error {
+    if len(args) == 0 || args[0] != "rewrap" {
+        return errors.New(piiUsage)
+    }
+    if store.rewrapPII == nil {
+        return errors.New("PII encryption is not enabled, set PII_ENCRYPTION_KEY_FILE")
+    }
+
+    batchSize := defaultRewrapBatch
+    if len(args) > 1 {
+        var err error
+        batchSize, err = strconv.Atoi(args[1])
+        if err != nil || batchSize < 1 {
+            return fmt.Errorf("invalid batch size %q", args[1])
+        }
+    }
+
+    n, err := store.rewrapPII(ctx, batchSize)
+    if err != nil {
+        return err
+    }
+    fmt.Printf("re-encrypted %d delivery row(s)\n", n)
+    return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+    "context"
+    "log/slog"
+    "os"
+    "os/signal"
+    "reflect"
+    "syscall"
+
+    "L0/internal/config"
+    mw "L0/internal/middleware"
+    "L0/internal/service"
+)
+
+// reloader перечитывает конфигурацию по SIGHUP и применяет то, что можно
+// поменять на ходу: лимиты запросов, TTL кэша и уровень логов. Остальные
+// настройки вступают в силу только после рестарта
+type reloader struct {
+    current  *config.Config // действующая конфигурация
+    logLevel *slog.LevelVar
+    limiter  *mw.RateLimiter // nil, если RATE_LIMITER_ENABLED=false
+    orders   service.OrderService
+}
+
+func (r *reloader) Run(ctx context.Context) {
+    hup := make(chan os.Signal, 1)
+    signal.Notify(hup, syscall.SIGHUP)
+    defer signal.Stop(hup)
+
+    for {
+        select {
+        case <-hup:
+            r.reload()
+        case <-ctx.Done():
+            return
+        }
+    }
+}
+
+func (r *reloader) reload() {
+    slog.Info("Reloading config...")
+
+    cfg, err := config.Load()
+    if err != nil {
+        slog.Error("Config reload failed, keeping current config", "error", err)
+        return
+    }
+
+    next := *r.current
+
+    if r.limiter != nil {
+        if err := r.limiter.SetLimits(cfg.RateLimiter); err != nil {
+            slog.Error("Config reload failed, keeping current config", "error", err)
+            return
+        }
+        next.RateLimiter.RPS = cfg.RateLimiter.RPS
+        next.RateLimiter.Burst = cfg.RateLimiter.Burst
+        next.RateLimiter.Routes = cfg.RateLimiter.Routes
+        next.RateLimiter.TrustedProxies = cfg.RateLimiter.TrustedProxies
+    }
+
+    // Уровень уже проверен в config.Validate
+    level, _ := config.ParseLogLevel(cfg.LogLevel)
+    r.logLevel.Set(level)
+    next.LogLevel = cfg.LogLevel
+
+    r.orders.SetCacheTTL(cfg.Cache.TTL)
+    next.Cache.TTL = cfg.Cache.TTL
+
+    if changed := changedSections(&next, cfg); len(changed) > 0 {
+        slog.Warn("Some config changes require a restart and were not applied", "sections", changed)
+    }
+
+    r.current = &next
+    slog.Info("Config reloaded",
+        "log_level", level.String(),
+        "cache_ttl", next.Cache.TTL.String(),
+        "rate_limiter_rps", next.RateLimiter.RPS,
+        "rate_limiter_burst", next.RateLimiter.Burst,
+    )
+}
+
+// changedSections возвращает поля Config верхнего уровня, которые различаются
+func changedSections(current, loaded *config.Config) []string {
+    a, b := reflect.ValueOf(*current), reflect.ValueOf(*loaded)
+
+    var changed []string
+    for i := 0; i < a.NumField(); i++ {
+        if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+            changed = append(changed, a.Type().Field(i).Name)
+        }
+    }
+    return changed
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+
+    "L0/internal/config"
+    "L0/internal/migrations"
+    "L0/internal/pii"
+    "L0/internal/repository"
+    "L0/internal/repository/memory"
+    "L0/internal/repository/postgres"
+    "L0/internal/repository/sqlite"
+    tHTTP "L0/internal/transport/http"
+
+    "github.com/exaring/otelpgx"
+    "github.com/jackc/pgx/v5/pgxpool"
+)
+
+// storage - хранилище заказов, выбранное через STORAGE_ENGINE
+type storage struct {
+    repo        repository.OrderRepository
+    apiKeys     repository.APIKeyRepository // nil, если таблицы api_keys нет
+    outbox      repository.OutboxRepository // nil, если хранилище не пишет outbox
+    rewrapPII   func(ctx context.Context, batchSize int) (int, error) // nil, если хранилище не шифрует персональные данные
+    migrator    *migrations.Migrator // nil, если у хранилища нет схемы
+    autoMigrate bool                 // применять миграции при старте независимо от DB_MIGRATE_ON_START
+    checks      []tHTTP.HealthCheck
+    close       func()
+}
+
+func openStorage(ctx context.Context, cfg *config.Config) (*storage, error) {
+    if cfg.PII.EncryptionKeyFile != "" && cfg.Storage.Engine != config.StoragePostgres {
+        return nil, fmt.Errorf("PII_ENCRYPTION_KEY_FILE is supported only by %s storage", config.StoragePostgres)
+    }
+    if cfg.Outbox.Enabled && cfg.Storage.Engine != config.StoragePostgres {
+        return nil, fmt.Errorf("OUTBOX_ENABLED is supported only by %s storage", config.StoragePostgres)
+    }
+
+    switch cfg.Storage.Engine {
+    case config.StorageMemory:
+        slog.Warn("Using in-memory storage, orders will be lost on restart")
+        return &storage{repo: memory.New(), close: func() {}}, nil
+
+    case config.StoragePostgres:
+        return openPostgres(ctx, cfg)
+
+    case config.StorageSQLite:
+        return openSQLite(ctx, cfg)
+
+    default:
+        return nil, fmt.Errorf("unknown storage engine %q", cfg.Storage.Engine)
+    }
+}
+
+func openPostgres(ctx context.Context, cfg *config.Config) (*storage, error) {
+    // Ключи читаются до подключения: без них сервис всё равно не сможет работать с зашифрованными строками
+    var keyring *pii.Keyring
+    if cfg.PII.EncryptionKeyFile != "" {
+        var err error
+        keyring, err = pii.LoadKeyring(cfg.PII.EncryptionKeyFile)
+        if err != nil {
+            return nil, fmt.Errorf("load PII encryption keys: %w", err)
+        }
+        slog.Info("PII encryption is enabled", "primary_key", keyring.Primary())
+    }
+
+    poolCfg, err := pgxpool.ParseConfig(cfg.DBDSN)
+    if err != nil {
+        return nil, fmt.Errorf("parse DB_DSN: %w", err)
+    }
+    // Span на каждый запрос, батч и COPY. Параметры запросов в span'ы не попадают
+    poolCfg.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
+
+    pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+    if err != nil {
+        return nil, fmt.Errorf("connect to database: %w", err)
+    }
+
+    if err := pool.Ping(ctx); err != nil {
+        pool.Close()
+        return nil, fmt.Errorf("ping database: %w", err)
+    }
+
+    slog.Info("Connected to database")
+
+    migrator, err := migrations.NewPostgres(pool)
+    if err != nil {
+        pool.Close()
+        return nil, fmt.Errorf("load migrations: %w", err)
+    }
+
+    check := tHTTP.HealthCheck{
+        Name: "postgres",
+        Check: func(ctx context.Context) (map[string]any, error) {
+            stat := pool.Stat()
+            details := map[string]any{
+                "total_conns":    stat.TotalConns(),
+                "idle_conns":     stat.IdleConns(),
+                "acquired_conns": stat.AcquiredConns(),
+            }
+            return details, pool.Ping(ctx)
+        },
+    }
+
+    repo := postgres.New(pool, cfg).WithKeyring(keyring)
+    st := &storage{
+        repo:     repo,
+        apiKeys:  repo,
+        outbox:   repo,
+        migrator: migrator,
+        checks:   []tHTTP.HealthCheck{check},
+        close:    pool.Close,
+    }
+    if keyring != nil {
+        st.rewrapPII = repo.RewrapPII
+    }
+    return st, nil
+}
+
+// openSQLite открывает файл БД. Файл принадлежит одному процессу,
+// поэтому схема всегда приводится к актуальной версии при старте.
+func openSQLite(ctx context.Context, cfg *config.Config) (*storage, error) {
+    db, err := sqlite.Open(cfg.Storage.SQLitePath)
+    if err != nil {
+        return nil, fmt.Errorf("open sqlite: %w", err)
+    }
+
+    if err := db.PingContext(ctx); err != nil {
+        db.Close()
+        return nil, fmt.Errorf("ping sqlite: %w", err)
+    }
+
+    slog.Info("Opened SQLite database", "path", cfg.Storage.SQLitePath)
+
+    migrator, err := migrations.NewSQLite(db)
+    if err != nil {
+        db.Close()
+        return nil, fmt.Errorf("load migrations: %w", err)
+    }
+
+    check := tHTTP.HealthCheck{
+        Name: "sqlite",
+        Check: func(ctx context.Context) (map[string]any, error) {
+            stat := db.Stats()
+            details := map[string]any{
+                "open_conns": stat.OpenConnections,
+                "in_use":     stat.InUse,
+                "idle":       stat.Idle,
+            }
+            return details, db.PingContext(ctx)
+        },
+    }
+
+    repo := sqlite.New(db)
+    return &storage{
+        repo:        repo,
+        apiKeys:     repo,
+        migrator:    migrator,
+        autoMigrate: true,
+        checks:      []tHTTP.HealthCheck{check},
+        close:       func() { db.Close() },
+    }, nil
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "os"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/BurntSushi/toml"
+    "github.com/ilyakaznacheev/cleanenv"
+    "github.com/joho/godotenv"
+    "gopkg.in/yaml.v3"
+)
+
+// configFileEnv - переменная с путём к необязательному файлу конфигурации
+const configFileEnv = "CONFIG_FILE"
+
+// Переменные окружения, выставленные из файла при прошлой загрузке.
+// При перезагрузке они заменяются значениями из новой версии файла
+var (
+    fileEnvMu sync.Mutex
+    fileEnv   = map[string]string{}
+)
+
+// Load читает конфигурацию в порядке возрастания приоритета: значения по
+// умолчанию, файл CONFIG_FILE (YAML или TOML), переменные окружения и .env.
+// Ключи файла совпадают с именами переменных: вложенные секции склеиваются
+// через "_", списки - через ",". Например, kafka: {brokers: [a, b]} - это
+// KAFKA_BROKERS=a,b. Ошибки файла и проверки значений возвращаются все сразу.
+// Load можно вызывать повторно, чтобы перечитать файл
+func Load() (*Config, error) {
+    // Для локальной разработки подгружаем .env файл
+    if err := godotenv.Load(); err != nil {
+        slog.Info("No .env file found, reading from environment variables")
+    }
+
+    var errs []error
+
+    values := map[string]string{}
+    if path := os.Getenv(configFileEnv); path != "" {
+        var err error
+        values, err = readFile(path)
+        if err != nil {
+            return nil, fmt.Errorf("config file %s: %w", path, err)
+        }
+        errs = append(errs, unknownKeys(path, values)...)
+    }
+    if err := applyFileEnv(values); err != nil {
+        return nil, err
+    }
+
+    var cfg Config
+    if err := cleanenv.ReadEnv(&cfg); err != nil {
+        return nil, fmt.Errorf("read config: %w", err)
+    }
+
+    if err := cfg.Validate(); err != nil {
+        errs = append(errs, err)
+    }
+    if len(errs) > 0 {
+        return nil, errors.Join(errs...)
+    }
+
+    if cfg.DBDSN == "" && cfg.Storage.Engine == StoragePostgres {
+        cfg.DBDSN = cfg.DB.DSN()
+    }
+
+    return &cfg, nil
+}
+
+// readFile разбирает файл и раскладывает его в пары "имя переменной - значение"
+func readFile(path string) (map[string]string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+
+    raw := map[string]any{}
+    switch ext := strings.ToLower(filepath.Ext(path)); ext {
+    case ".yaml", ".yml":
+        err = yaml.Unmarshal(data, &raw)
+    case ".toml":
+        err = toml.Unmarshal(data, &raw)
+    default:
+        return nil, fmt.Errorf("unsupported format %q, expected .yaml, .yml or .toml", ext)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    values := map[string]string{}
+    if err := flatten("", raw, values); err != nil {
+        return nil, err
+    }
+    return values, nil
+}
+
+func flatten(prefix string, node map[string]any, out map[string]string) error {
+    for key, value := range node {
+        name := strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+        if prefix != "" {
+            name = prefix + "_" + name
+        }
+
+        switch v := value.(type) {
+        case nil:
+        case map[string]any:
+            if err := flatten(name, v, out); err != nil {
+                return err
+            }
+        case []any:
+            items := make([]string, len(v))
+            for i, item := range v {
+                switch item.(type) {
+                case map[string]any, []any:
+                    return fmt.Errorf("%s: list items must be plain values", name)
+                }
+                items[i] = fmt.Sprint(item)
+            }
+            out[name] = strings.Join(items, ",")
+        default:
+            out[name] = fmt.Sprint(v)
+        }
+    }
+    return nil
+}
+
+// applyFileEnv выставляет значения из файла тем переменным, которые не заданы
+// в окружении. Значения из прошлой версии файла сначала убираются, если их
+// с тех пор никто не поменял
+func applyFileEnv(values map[string]string) error {
+    fileEnvMu.Lock()
+    defer fileEnvMu.Unlock()
+
+    for key, value := range fileEnv {
+        if current, ok := os.LookupEnv(key); ok && current == value {
+            if err := os.Unsetenv(key); err != nil {
+                return fmt.Errorf("unset %s: %w", key, err)
+            }
+        }
+    }
+
+    fileEnv = make(map[string]string, len(values))
+    for key, value := range values {
+        if _, ok := os.LookupEnv(key); ok {
+            continue
+        }
+        if err := os.Setenv(key, value); err != nil {
+            return fmt.Errorf("set %s: %w", key, err)
+        }
+        fileEnv[key] = value
+    }
+    return nil
+}
+
+// unknownKeys находит опечатки в файле: ключи, которым не соответствует ни одна переменная
+func unknownKeys(path string, values map[string]string) []error {
+    known := map[string]bool{}
+    envNames(reflect.TypeOf(Config{}), "", known)
+
+    var errs []error
+    for key := range values {
+        if !known[key] {
+            errs = append(errs, fmt.Errorf("config file %s: unknown setting %s", path, key))
+        }
+    }
+    sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+    return errs
+}
+
+// envNames собирает имена переменных так же, как cleanenv: env-prefix
+// вложенной структуры добавляется к env её полей
+func envNames(t reflect.Type, prefix string, out map[string]bool) {
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        if env, ok := field.Tag.Lookup("env"); ok {
+            for _, name := range strings.Split(env, ",") {
+                out[prefix+strings.TrimSpace(name)] = true
+            }
+            continue
+        }
+        if field.Type.Kind() == reflect.Struct {
+            envNames(field.Type, prefix+field.Tag.Get("env-prefix"), out)
+        }
+    }
+}
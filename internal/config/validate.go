@@ -0,0 +1,136 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "log/slog"
+    "net/netip"
+    "strings"
+    "time"
+)
+
+// Validate проверяет значения, которые нельзя описать тегами cleanenv.
+// Возвращает все найденные ошибки сразу, а не только первую
+func (c *Config) Validate() error {
+    var errs []error
+    check := func(ok bool, format string, args ...any) {
+        if !ok {
+            errs = append(errs, fmt.Errorf(format, args...))
+        }
+    }
+    positive := func(name string, d time.Duration) {
+        check(d > 0, "%s must be positive, got %s", name, d)
+    }
+
+    if _, err := ParseLogLevel(c.LogLevel); err != nil {
+        errs = append(errs, err)
+    }
+
+    positive("HTTP_READ_TIMEOUT", c.HTTPServer.ReadTimeout)
+    positive("HTTP_WRITE_TIMEOUT", c.HTTPServer.WriteTimeout)
+    positive("HTTP_SHUTDOWN_TIMEOUT", c.HTTPServer.ShutdownTimeout)
+
+    switch c.Storage.Engine {
+    case StoragePostgres:
+        if c.DBDSN == "" {
+            db := c.DB
+            check(db.Host != "" && db.Port != "" && db.User != "" && db.Pass != "" && db.Name != "",
+                "DB_DSN or DB_HOST, DB_PORT, DB_USER, DB_PASSWORD and DB_NAME are required for postgres storage")
+        }
+    case StorageSQLite:
+        check(c.Storage.SQLitePath != "", "STORAGE_SQLITE_PATH is required for sqlite storage")
+    case StorageMemory:
+    default:
+        errs = append(errs, fmt.Errorf("unknown STORAGE_ENGINE %q, expected %s, %s or %s",
+            c.Storage.Engine, StoragePostgres, StorageSQLite, StorageMemory))
+    }
+
+    check(len(c.Kafka.Brokers) > 0, "KAFKA_BROKERS is required")
+    check(c.Kafka.Topic != "", "KAFKA_TOPIC is required")
+    check(c.Kafka.Workers >= 1, "KAFKA_WORKERS must be at least 1, got %d", c.Kafka.Workers)
+    check(c.Kafka.QueueSize >= 1, "KAFKA_QUEUE_SIZE must be at least 1, got %d", c.Kafka.QueueSize)
+    check(c.Kafka.BatchSize >= 1, "KAFKA_BATCH_SIZE must be at least 1, got %d", c.Kafka.BatchSize)
+    // Пул воркеров обрабатывает сообщения по одному, пакетный режим работает только в одном потоке
+    check(c.Kafka.Workers <= 1 || c.Kafka.BatchSize <= 1,
+        "KAFKA_WORKERS > 1 and KAFKA_BATCH_SIZE > 1 cannot be combined, batch mode requires KAFKA_WORKERS=1")
+    check(c.Kafka.MaxLag >= 0, "KAFKA_MAX_LAG must not be negative, got %d", c.Kafka.MaxLag)
+    positive("KAFKA_COMMIT_TIMEOUT", c.Kafka.CommitTimeout)
+    positive("KAFKA_BATCH_TIMEOUT", c.Kafka.BatchTimeout)
+
+    if c.Outbox.Enabled {
+        check(c.Storage.Engine == StoragePostgres, "OUTBOX_ENABLED requires postgres storage")
+        check(c.Outbox.Topic != "", "OUTBOX_TOPIC is required when OUTBOX_ENABLED=true")
+        check(c.Outbox.BatchSize >= 1, "OUTBOX_BATCH_SIZE must be at least 1, got %d", c.Outbox.BatchSize)
+        positive("OUTBOX_POLL_INTERVAL", c.Outbox.PollInterval)
+        positive("OUTBOX_PUBLISH_TIMEOUT", c.Outbox.PublishTimeout)
+        positive("OUTBOX_RETENTION", c.Outbox.Retention)
+        positive("OUTBOX_CLEANUP_INTERVAL", c.Outbox.CleanupInterval)
+    }
+
+    check(c.Cache.Size > 0, "CACHE_SIZE must be positive, got %d", c.Cache.Size)
+    positive("CACHE_TTL", c.Cache.TTL)
+    check(c.Cache.WarmUpSize >= 0, "CACHE_WARMUP_SIZE must not be negative, got %d", c.Cache.WarmUpSize)
+
+    check(c.Stream.BufferSize > 0, "STREAM_BUFFER_SIZE must be positive, got %d", c.Stream.BufferSize)
+    check(c.Stream.HistorySize >= 0, "STREAM_HISTORY_SIZE must not be negative, got %d", c.Stream.HistorySize)
+
+    if c.RateLimiter.Enabled {
+        check(c.RateLimiter.RPS > 0, "RATE_LIMITER_RPS must be positive, got %v", c.RateLimiter.RPS)
+        check(c.RateLimiter.Burst > 0, "RATE_LIMITER_BURST must be positive, got %d", c.RateLimiter.Burst)
+        positive("RATE_LIMITER_IDLE_TTL", c.RateLimiter.IdleTTL)
+    }
+    for _, proxy := range c.RateLimiter.TrustedProxies {
+        check(validProxy(strings.TrimSpace(proxy)), "RATE_LIMITER_TRUSTED_PROXIES: invalid address or CIDR %q", proxy)
+    }
+
+    if c.Auth.KeysFromDB {
+        positive("AUTH_CACHE_TTL", c.Auth.CacheTTL)
+    }
+
+    check(c.Producer.DataPath != "", "PRODUCER_DATA_PATH is required")
+    positive("PRODUCER_DELAY", c.Producer.Delay)
+
+    positive("MONITOR_GOROUTINES_INTERVAL", c.Monitor.GoroutinesInterval)
+    if c.Monitor.PprofEnabled {
+        positive("MONITOR_PPROF_CPU_DURATION", c.Monitor.PprofCPUDuration)
+    }
+
+    switch c.Tracing.Exporter {
+    case "", "none", "otlp", "stdout":
+    default:
+        errs = append(errs, fmt.Errorf("unknown TRACING_EXPORTER %q, expected otlp, stdout or none", c.Tracing.Exporter))
+    }
+    check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+        "TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+    positive("RETRY_MAX_ELAPSED_TIME_DB", c.Retry.MaxElapsedTimeDB)
+    positive("RETRY_MAX_ELAPSED_TIME_READ", c.Retry.MaxElapsedTimeRead)
+    positive("RETRY_INITIAL_INTERVAL", c.Retry.InitialInterval)
+    positive("RETRY_MAX_INTERVAL_DB", c.Retry.MaxIntervalDB)
+    positive("RETRY_MAX_INTERVAL_READ", c.Retry.MaxIntervalRead)
+
+    positive("HEALTH_CHECK_TIMEOUT", c.Health.CheckTimeout)
+
+    return errors.Join(errs...)
+}
+
+// ParseLogLevel разбирает LOG_LEVEL: debug, info, warn или error без учёта регистра
+func ParseLogLevel(s string) (slog.Level, error) {
+    var level slog.Level
+    if err := level.UnmarshalText([]byte(s)); err != nil {
+        return 0, fmt.Errorf("invalid LOG_LEVEL %q, expected debug, info, warn or error", s)
+    }
+    return level, nil
+}
+
+func validProxy(value string) bool {
+    if value == "" {
+        return true
+    }
+    if strings.Contains(value, "/") {
+        _, err := netip.ParsePrefix(value)
+        return err == nil
+    }
+    _, err := netip.ParseAddr(value)
+    return err == nil
+}
@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "l0"
+
+// HTTP
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Количество HTTP запросов по маршруту, методу и статусу.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Время обработки HTTP запроса.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	RateLimiterClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limiter_clients",
+		Help:      "Количество клиентов, для которых rate limiter хранит состояние.",
+	})
+
+	RateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Количество запросов, отклонённых rate limiter'ом, по маршруту.",
+	}, []string{"route"})
+)
+
+// Kafka consumer
+var (
+	KafkaMessagesProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_processed_total",
+		Help:      "Количество успешно обработанных сообщений.",
+	})
+
+	KafkaMessagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_failed_total",
+		Help:      "Количество сообщений, которые не удалось обработать, по классу ошибки.",
+	}, []string{"class"})
+
+	KafkaMessagesRetried = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_retried_total",
+		Help:      "Количество повторных попыток сохранить заказ из сообщения.",
+	})
+
+	KafkaCommitDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "commit_duration_seconds",
+		Help:      "Время коммита offset'ов.",
+		Buckets:   prometheus.DefBuckets,
+	})
+)
+
+// Transactional outbox
+var (
+	OutboxPublished = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "published_total",
+		Help:      "Количество событий outbox, опубликованных в Kafka.",
+	})
+
+	OutboxPublishErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "publish_errors_total",
+		Help:      "Количество неудачных попыток опубликовать события outbox.",
+	})
+
+	OutboxDeleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "deleted_total",
+		Help:      "Количество отправленных событий outbox, удалённых по сроку хранения.",
+	})
+)
+
+// Кэш заказов
+var (
+	CacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "hits_total",
+		Help:      "Количество попаданий в кэш заказов.",
+	})
+
+	CacheMisses = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "misses_total",
+		Help:      "Количество промахов кэша заказов.",
+	})
+
+	CacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cache",
+		Name:      "evictions_total",
+		Help:      "Количество заказов, вытесненных из кэша по размеру или TTL.",
+	})
+)
+
+// Подписки на новые заказы
+var (
+	OrderSubscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "subscribers",
+		Help:      "Текущее количество подписчиков на новые заказы.",
+	})
+
+	OrderEventsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "events_dropped_total",
+		Help:      "Количество событий, не доставленных медленным подписчикам.",
+	})
+)
+
+// Репозиторий
+var (
+	DBOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "operation_duration_seconds",
+		Help:      "Время операции репозитория с учётом повторных попыток.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "status"})
+
+	DBRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "retries_total",
+		Help:      "Количество повторных попыток операций репозитория.",
+	}, []string{"operation"})
+)
+
+// Status возвращает значение метки status для результата операции
+func Status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"L0/internal/config"
+	"L0/internal/models"
+	"L0/internal/repository"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+)
+
+// Сколько разных ключей из БД помнить одновременно
+const apiKeyCacheSize = 1024
+
+type apiKeyCtxKey struct{}
+
+// Authenticator проверяет API-ключ запроса по ключам из AUTH_KEYS и,
+// если задано, по таблице api_keys. Ключ передаётся в заголовке
+// Authorization: Bearer <key>, X-API-Key или паролем Basic-авторизации,
+// чтобы веб-интерфейс и Swagger UI открывались из браузера.
+// Ключи из БД кэшируются на AUTH_CACHE_TTL: отозванный ключ перестаёт
+// приниматься не позже чем через это время
+type Authenticator struct {
+	static map[string]models.APIKey // по хэшу ключа
+	store  repository.APIKeyRepository
+	cache  *expirable.LRU[string, models.APIKey]
+}
+
+// NewAuthenticator проверяет роли ключей из конфига. store может быть nil,
+// тогда используются только ключи из конфига
+func NewAuthenticator(cfg config.Auth, store repository.APIKeyRepository) (*Authenticator, error) {
+	a := &Authenticator{
+		static: make(map[string]models.APIKey, len(cfg.Keys)),
+		store:  store,
+	}
+
+	for _, key := range cfg.Keys {
+		role, err := models.ParseRole(key.Role)
+		if err != nil {
+			return nil, fmt.Errorf("api key %q: %w", key.Name, err)
+		}
+		a.static[models.HashAPIKey(key.Key)] = models.APIKey{Name: key.Name, Role: role}
+	}
+
+	if store != nil {
+		a.cache = expirable.NewLRU[string, models.APIKey](apiKeyCacheSize, nil, cfg.CacheTTL)
+	}
+
+	if len(a.static) == 0 && store == nil {
+		return nil, errors.New("authentication is enabled, but no api keys are configured")
+	}
+	return a, nil
+}
+
+// Handler отклоняет запросы без действующего ключа с 401,
+// владелец ключа доступен обработчикам через APIKeyFromContext
+func (a *Authenticator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw := requestAPIKey(r)
+		if raw == "" {
+			unauthorized(w, r, "api key is required")
+			return
+		}
+
+		ctx, err := a.Authenticate(r.Context(), raw)
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			unauthorized(w, r, "invalid api key")
+			return
+		}
+		if err != nil {
+			slog.Error("failed to check api key", "error", err, "request_id", middleware.GetReqID(r.Context()))
+			WriteError(w, r, http.StatusServiceUnavailable, models.ErrCodeUnavailable, "storage is temporarily unavailable")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Authenticate проверяет ключ и возвращает контекст с его владельцем для
+// APIKeyFromContext. Нужен транспортам без http.Request, например gRPC.
+// Для неизвестного ключа возвращает models.ErrAPIKeyNotFound
+func (a *Authenticator) Authenticate(ctx context.Context, raw string) (context.Context, error) {
+	key, err := a.lookup(ctx, models.HashAPIKey(raw))
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, apiKeyCtxKey{}, key), nil
+}
+
+func (a *Authenticator) lookup(ctx context.Context, hash string) (models.APIKey, error) {
+	if key, ok := a.static[hash]; ok {
+		return key, nil
+	}
+	if a.store == nil {
+		return models.APIKey{}, models.ErrAPIKeyNotFound
+	}
+	if key, ok := a.cache.Get(hash); ok {
+		return key, nil
+	}
+
+	key, err := a.store.GetAPIKey(ctx, hash)
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	// Роль в таблице ограничена CHECK, но лишняя проверка дешевле, чем выданные права
+	if _, err := models.ParseRole(string(key.Role)); err != nil {
+		return models.APIKey{}, fmt.Errorf("api key %q: %w", key.Name, err)
+	}
+
+	a.cache.Add(hash, key)
+	return key, nil
+}
+
+func requestAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return password
+	}
+	return ""
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	w.Header().Add("WWW-Authenticate", "Bearer")
+	w.Header().Add("WWW-Authenticate", `Basic realm="orders", charset="UTF-8"`)
+	WriteError(w, r, http.StatusUnauthorized, models.ErrCodeUnauthorized, message)
+}
+
+// RequireRole пропускает только ключи с ролью не ниже role.
+// Подключается после Authenticator.Handler
+func RequireRole(role models.Role) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := APIKeyFromContext(r.Context())
+			if !ok {
+				unauthorized(w, r, "api key is required")
+				return
+			}
+			if !key.Role.Allows(role) {
+				WriteError(w, r, http.StatusForbidden, models.ErrCodeForbidden, "role "+string(role)+" is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// APIKeyFromContext возвращает владельца ключа запроса.
+// ok == false, если аутентификация выключена
+func APIKeyFromContext(ctx context.Context) (models.APIKey, bool) {
+	key, ok := ctx.Value(apiKeyCtxKey{}).(models.APIKey)
+	return key, ok
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"L0/internal/config"
+	"L0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKeyStore struct {
+	keys  map[string]models.APIKey
+	err   error
+	calls int
+}
+
+func (s *fakeKeyStore) GetAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+	s.calls++
+	if s.err != nil {
+		return models.APIKey{}, s.err
+	}
+	key, ok := s.keys[keyHash]
+	if !ok {
+		return models.APIKey{}, models.ErrAPIKeyNotFound
+	}
+	return key, nil
+}
+
+// echoRole отвечает ролью ключа из контекста
+var echoRole = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	key, _ := APIKeyFromContext(r.Context())
+	_, _ = w.Write([]byte(key.Role))
+})
+
+func TestAuthenticator_KeyLocations(t *testing.T) {
+	auth, err := NewAuthenticator(config.Auth{Keys: config.APIKeys{{Name: "ops", Role: "support", Key: "secret"}}}, nil)
+	require.NoError(t, err)
+	handler := auth.Handler(echoRole)
+
+	tests := []struct {
+		name  string
+		setup func(r *http.Request)
+	}{
+		{name: "bearer", setup: func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }},
+		{name: "x-api-key", setup: func(r *http.Request) { r.Header.Set("X-API-Key", "secret") }},
+		{name: "basic password", setup: func(r *http.Request) { r.SetBasicAuth("anyone", "secret") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			tt.setup(req)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "support", w.Body.String())
+		})
+	}
+}
+
+func TestAuthenticator_Unauthorized(t *testing.T) {
+	auth, err := NewAuthenticator(config.Auth{Keys: config.APIKeys{{Name: "ops", Role: "viewer", Key: "secret"}}}, nil)
+	require.NoError(t, err)
+	handler := auth.Handler(echoRole)
+
+	for name, header := range map[string]string{"missing": "", "wrong": "Bearer nope"} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.Contains(t, w.Header().Values("WWW-Authenticate"), "Bearer")
+			assert.Equal(t, models.ErrCodeUnauthorized, decodeError(t, w).Code)
+		})
+	}
+}
+
+func TestAuthenticator_StoreIsCached(t *testing.T) {
+	store := &fakeKeyStore{keys: map[string]models.APIKey{
+		models.HashAPIKey("db-key"): {Name: "team", Role: models.RoleAdmin},
+	}}
+	auth, err := NewAuthenticator(config.Auth{CacheTTL: time.Minute}, store)
+	require.NoError(t, err)
+	handler := auth.Handler(echoRole)
+
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "db-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "admin", w.Body.String())
+	}
+	assert.Equal(t, 1, store.calls)
+}
+
+func TestAuthenticator_StoreUnavailable(t *testing.T) {
+	store := &fakeKeyStore{err: models.DatabaseError{Operation: "get", Err: errors.New("connection refused")}}
+	auth, err := NewAuthenticator(config.Auth{CacheTTL: time.Minute}, store)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "db-key")
+	w := httptest.NewRecorder()
+	auth.Handler(echoRole).ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	apiErr := decodeError(t, w)
+	assert.Equal(t, models.ErrCodeUnavailable, apiErr.Code)
+	assert.NotContains(t, apiErr.Message, "connection refused")
+}
+
+func TestNewAuthenticator_InvalidConfig(t *testing.T) {
+	_, err := NewAuthenticator(config.Auth{Keys: config.APIKeys{{Name: "ops", Role: "root", Key: "secret"}}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewAuthenticator(config.Auth{}, nil)
+	assert.Error(t, err)
+}
+
+func TestRequireRole(t *testing.T) {
+	auth, err := NewAuthenticator(config.Auth{Keys: config.APIKeys{
+		{Name: "viewer", Role: "viewer", Key: "v"},
+		{Name: "admin", Role: "admin", Key: "a"},
+	}}, nil)
+	require.NoError(t, err)
+	handler := auth.Handler(RequireRole(models.RoleSupport)(echoRole))
+
+	tests := []struct {
+		key        string
+		wantStatus int
+	}{
+		{key: "v", wantStatus: http.StatusForbidden},
+		{key: "a", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("X-API-Key", tt.key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, tt.wantStatus, w.Code)
+		if tt.wantStatus == http.StatusForbidden {
+			assert.Equal(t, models.ErrCodeForbidden, decodeError(t, w).Code)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"L0/internal/models"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// WriteError отвечает ошибкой в едином формате models.ErrorResponse
+// и подставляет ID запроса из middleware.RequestID
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, message string, details ...models.InvalidOrderDataError) {
+	resp := models.ErrorResponse{Error: models.APIError{
+		Code:      code,
+		Message:   message,
+		RequestID: middleware.GetReqID(r.Context()),
+		Details:   details,
+	}}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("failed to encode error response", "error", err)
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"L0/internal/config"
+	"L0/internal/models"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) models.APIError {
+	t.Helper()
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var resp models.ErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	return resp.Error
+}
+
+func TestWriteError(t *testing.T) {
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, http.StatusUnprocessableEntity, models.ErrCodeValidationFailed, "order validation failed",
+			models.InvalidOrderDataError{Field: "items", Message: "must not be empty"})
+	})
+	handler = middleware.RequestID(handler)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	apiErr := decodeError(t, w)
+	assert.Equal(t, models.ErrCodeValidationFailed, apiErr.Code)
+	assert.Equal(t, "order validation failed", apiErr.Message)
+	assert.NotEmpty(t, apiErr.RequestID)
+	assert.Equal(t, []models.InvalidOrderDataError{{Field: "items", Message: "must not be empty"}}, apiErr.Details)
+}
+
+func TestRecoverer(t *testing.T) {
+	handler := Recoverer()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	apiErr := decodeError(t, w)
+	assert.Equal(t, models.ErrCodeInternal, apiErr.Code)
+	assert.NotContains(t, apiErr.Message, "boom")
+}
+
+func TestRecoverer_RepanicsAbortHandler(t *testing.T) {
+	handler := Recoverer()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestRateLimiter_ErrorEnvelope(t *testing.T) {
+	limiter, err := NewRateLimiter(config.RateLimiter{RPS: 1, Burst: 1})
+	require.NoError(t, err)
+	handler := limiter.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, models.ErrCodeRateLimited, decodeError(t, w).Code)
+}
@@ -38,7 +38,7 @@ func NewCustomSlogLogger() func(next http.Handler) http.Handler {
 
 			defer func() {
 				// Финальный лог
-				entry.Info("request completed",
+				entry.InfoContext(r.Context(), "request completed",
 					slog.Int("status", ww.Status()),
 					slog.Int("bytes", ww.BytesWritten()),
 					slog.String("duration", time.Since(t1).String()),
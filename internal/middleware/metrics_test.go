@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"L0/internal/metrics"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_UsesRoutePattern(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Metrics())
+	r.Get("/order/{order_uid}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	counter := metrics.HTTPRequestsTotal.WithLabelValues("/order/{order_uid}", http.MethodGet, "404")
+	before := testutil.ToFloat64(counter)
+
+	for _, uid := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodGet, "/order/"+uid, nil)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, before+2, testutil.ToFloat64(counter))
+}
+
+func TestMetrics_CollapsesUnknownMethods(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Metrics())
+	r.Get("/orders", func(w http.ResponseWriter, r *http.Request) {})
+
+	counter := metrics.HTTPRequestsTotal.WithLabelValues("unmatched", "OTHER", "405")
+	before := testutil.ToFloat64(counter)
+
+	for _, method := range []string{"FOO", "BAR"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/orders", nil))
+	}
+
+	assert.Equal(t, before+2, testutil.ToFloat64(counter))
+}
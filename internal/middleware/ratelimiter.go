@@ -1,38 +1,301 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"L0/internal/config"
+	"L0/internal/metrics"
+	"L0/internal/models"
+
+	"github.com/go-chi/chi/v5"
 	"golang.org/x/time/rate"
 )
 
-func IPRateLimiter(rps float64, burst int) func(next http.Handler) http.Handler {
-	clients := make(map[string]*rate.Limiter)
-	var mu sync.Mutex
+// RateLimiter ограничивает частоту запросов с одного IP.
+// У каждого клиента свой token bucket: общий или отдельный для маршрута из RATE_LIMITER_ROUTES.
+// Клиенты, которые не обращались дольше IdleTTL, удаляются из памяти
+type RateLimiter struct {
+	limits  atomic.Pointer[limits] // меняется через SetLimits без остановки сервера
+	idleTTL time.Duration
 
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			ip, _, err := net.SplitHostPort(r.RemoteAddr)
-			if err != nil {
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-				return
+	mu      sync.Mutex
+	clients map[string]*client
+
+	now func() time.Time // подменяется в тестах
+}
+
+type limits struct {
+	limit   config.RouteLimit
+	routes  config.RouteLimits
+	proxies []netip.Prefix
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func NewRateLimiter(cfg config.RateLimiter) (*RateLimiter, error) {
+	lim, err := newLimits(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &RateLimiter{
+		idleTTL: cfg.IdleTTL,
+		clients: make(map[string]*client),
+		now:     time.Now,
+	}
+	l.limits.Store(lim)
+	return l, nil
+}
+
+func newLimits(cfg config.RateLimiter) (*limits, error) {
+	proxies, err := parseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &limits{
+		limit:   config.RouteLimit{RPS: cfg.RPS, Burst: cfg.Burst},
+		routes:  cfg.Routes,
+		proxies: proxies,
+	}, nil
+}
+
+// SetLimits применяет новые RPS, BURST, ROUTES и TRUSTED_PROXIES.
+// Накопленные токены клиентов сохраняются, меняется только скорость и размер
+// bucket'а. Клиенты маршрута, для которого убрали отдельный лимит, удаляются и
+// при следующем запросе попадают в общий bucket. IdleTTL не меняется
+func (l *RateLimiter) SetLimits(cfg config.RateLimiter) error {
+	lim, err := newLimits(cfg)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits.Store(lim)
+
+	now := l.now()
+	for key, c := range l.clients {
+		route := key[:strings.LastIndex(key, "|")]
+		limit := lim.limit
+		if route != "" {
+			var ok bool
+			if limit, ok = lim.routes[route]; !ok {
+				delete(l.clients, key)
+				continue
 			}
+		}
+		c.limiter.SetLimitAt(now, rate.Limit(limit.RPS))
+		c.limiter.SetBurstAt(now, limit.Burst)
+	}
+	metrics.RateLimiterClients.Set(float64(len(l.clients)))
+	return nil
+}
+
+// parseTrustedProxies принимает как CIDR, так и одиночные адреса
+func parseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	proxies := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
 
-			mu.Lock()
-			if _, found := clients[ip]; !found {
-				clients[ip] = rate.NewLimiter(rate.Limit(rps), burst)
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
 			}
-			limiter := clients[ip]
-			mu.Unlock()
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// Handler - middleware для chi. Маршрут определяется через дерево роутера,
+// поэтому его можно подключать через Use на уровне группы
+func (l *RateLimiter) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, ok := l.clientIP(r)
+		if !ok {
+			WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "internal server error")
+			return
+		}
+
+		route, limit := l.routeLimit(r)
+		now := l.now()
+		limiter := l.limiter(route+"|"+ip.String(), limit, now)
+
+		allowed := limiter.AllowN(now, 1)
+		tokens := limiter.TokensAt(now)
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+		h.Set("RateLimit-Remaining", strconv.Itoa(max(int(math.Floor(tokens)), 0)))
+		h.Set("RateLimit-Reset", strconv.Itoa(secondsUntil(float64(limit.Burst)-tokens, limit.RPS)))
+
+		if !allowed {
+			metrics.RateLimitedTotal.WithLabelValues(metricsRoute(route)).Inc()
+			h.Set("Retry-After", strconv.Itoa(max(secondsUntil(1-tokens, limit.RPS), 1)))
+			WriteError(w, r, http.StatusTooManyRequests, models.ErrCodeRateLimited, "too many requests, slow down")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeLimit ищет отдельный лимит для маршрута запроса. Для маршрутов без
+// своего лимита ключ пустой: у клиента один общий bucket на все такие маршруты
+func (l *RateLimiter) routeLimit(r *http.Request) (string, config.RouteLimit) {
+	lim := l.limits.Load()
+	if len(lim.routes) == 0 {
+		return "", lim.limit
+	}
 
-			if !limiter.Allow() {
-				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
-				return
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return "", lim.limit
+	}
+
+	route := r.Method + " " + rctx.Routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	if limit, ok := lim.routes[route]; ok {
+		return route, limit
+	}
+	return "", lim.limit
+}
+
+func (l *RateLimiter) limiter(key string, limit config.RouteLimit, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	c, found := l.clients[key]
+	if !found {
+		c = &client{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst)}
+		l.clients[key] = c
+		metrics.RateLimiterClients.Set(float64(len(l.clients)))
+	}
+	c.lastSeen = now
+	return c.limiter
+}
+
+// clientIP берёт адрес из X-Forwarded-For/X-Real-IP, только если запрос пришёл
+// от доверенного прокси. Иначе заголовки мог подделать сам клиент
+func (l *RateLimiter) clientIP(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	remote = remote.Unmap()
+
+	lim := l.limits.Load()
+	if !lim.trusted(remote) {
+		return remote, true
+	}
+
+	// Идём справа налево: правые адреса добавили наши прокси, первый недоверенный - клиент
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		var leftmost netip.Addr
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
 			}
+			addr = addr.Unmap()
+			if !lim.trusted(addr) {
+				return addr, true
+			}
+			leftmost = addr
+		}
+		if leftmost.IsValid() {
+			return leftmost, true
+		}
+	}
+
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap(), true
+	}
+
+	return remote, true
+}
+
+func (l *limits) trusted(addr netip.Addr) bool {
+	for _, prefix := range l.proxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run периодически удаляет простаивающих клиентов, пока не отменён ctx
+func (l *RateLimiter) Run(ctx context.Context) {
+	if l.idleTTL <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(l.idleTTL / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.evict(l.now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *RateLimiter) evict(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, c := range l.clients {
+		if now.Sub(c.lastSeen) > l.idleTTL {
+			delete(l.clients, key)
+		}
+	}
+	metrics.RateLimiterClients.Set(float64(len(l.clients)))
+}
+
+// secondsUntil - через сколько целых секунд накопится tokens токенов
+func secondsUntil(tokens, rps float64) int {
+	if tokens <= 0 || rps <= 0 {
+		return 0
+	}
+	return int(math.Ceil(tokens / rps))
+}
 
-			next.ServeHTTP(w, r)
-		})
+func metricsRoute(route string) string {
+	if route == "" {
+		return "default"
 	}
+	return route
 }
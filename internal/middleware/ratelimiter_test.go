@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"L0/internal/config"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimiter(t *testing.T, cfg config.RateLimiter) (*RateLimiter, *time.Time) {
+	t.Helper()
+	limiter, err := NewRateLimiter(cfg)
+	require.NoError(t, err)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func newTestRouter(limiter *RateLimiter) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(limiter.Handler)
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r.Get("/orders", ok)
+	r.Post("/orders", ok)
+	r.Get("/order/{order_uid}", ok)
+	return r
+}
+
+func doRequest(handler http.Handler, method, path, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter_Headers(t *testing.T) {
+	limiter, now := newTestRateLimiter(t, config.RateLimiter{RPS: 1, Burst: 2})
+	router := newTestRouter(limiter)
+
+	w := doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1234", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1234", nil)
+	w = doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1234", nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	*now = now.Add(time.Second)
+	w = doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimiter_ClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:1000", want: "203.0.113.7"},
+		{name: "untrusted sender cannot spoof XFF", remoteAddr: "203.0.113.7:1000", headers: map[string]string{"X-Forwarded-For": "1.1.1.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy XFF", remoteAddr: "10.0.0.2:1000", headers: map[string]string{"X-Forwarded-For": "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "skips trusted hops from the right", remoteAddr: "10.0.0.2:1000", headers: map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.1, 10.0.0.3"}, want: "198.51.100.1"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.2:1000", headers: map[string]string{"X-Forwarded-For": "10.0.0.4, 10.0.0.3"}, want: "10.0.0.4"},
+		{name: "trusted proxy X-Real-IP", remoteAddr: "127.0.0.1:1000", headers: map[string]string{"X-Real-IP": "198.51.100.2"}, want: "198.51.100.2"},
+		{name: "trusted proxy without headers", remoteAddr: "127.0.0.1:1000", want: "127.0.0.1"},
+	}
+
+	limiter, _ := newTestRateLimiter(t, config.RateLimiter{RPS: 1, Burst: 1, TrustedProxies: []string{"10.0.0.0/8", "127.0.0.1"}})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			ip, ok := limiter.clientIP(req)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, ip.String())
+		})
+	}
+}
+
+func TestRateLimiter_ClientsBehindProxyHaveSeparateBuckets(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, config.RateLimiter{RPS: 1, Burst: 1, TrustedProxies: []string{"10.0.0.1"}})
+	router := newTestRouter(limiter)
+
+	first := map[string]string{"X-Forwarded-For": "198.51.100.1"}
+	second := map[string]string{"X-Forwarded-For": "198.51.100.2"}
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", first).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", first).Code)
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", second).Code)
+}
+
+func TestRateLimiter_RouteLimits(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, config.RateLimiter{
+		RPS:    1,
+		Burst:  1,
+		Routes: config.RouteLimits{"POST /orders": {RPS: 1, Burst: 3}},
+	})
+	router := newTestRouter(limiter)
+
+	for range 3 {
+		w := doRequest(router, http.MethodPost, "/orders", "10.0.0.1:1", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodPost, "/orders", "10.0.0.1:1", nil).Code)
+
+	// Маршруты без своего лимита делят общий bucket и не зависят от POST /orders
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", nil).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodGet, "/order/abc", "10.0.0.1:1", nil).Code)
+}
+
+func TestRateLimiter_EvictsIdleClients(t *testing.T) {
+	limiter, now := newTestRateLimiter(t, config.RateLimiter{RPS: 1, Burst: 1, IdleTTL: time.Minute})
+	router := newTestRouter(limiter)
+
+	doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", nil)
+	*now = now.Add(30 * time.Second)
+	doRequest(router, http.MethodGet, "/orders", "10.0.0.2:1", nil)
+
+	*now = now.Add(45 * time.Second)
+	limiter.evict(*now)
+
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	assert.Len(t, limiter.clients, 1)
+	assert.Contains(t, limiter.clients, "|10.0.0.2")
+}
+
+func TestNewRateLimiter_InvalidTrustedProxy(t *testing.T) {
+	_, err := NewRateLimiter(config.RateLimiter{RPS: 1, Burst: 1, TrustedProxies: []string{"not-an-ip"}})
+	assert.Error(t, err)
+}
+
+func TestRateLimiter_SetLimits(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, config.RateLimiter{
+		RPS:    1,
+		Burst:  1,
+		Routes: config.RouteLimits{"POST /orders": {RPS: 1, Burst: 3}},
+	})
+	router := newTestRouter(limiter)
+
+	require.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", nil).Code)
+	require.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/orders", "10.0.0.1:1", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", nil).Code)
+
+	require.NoError(t, limiter.SetLimits(config.RateLimiter{RPS: 1, Burst: 3}))
+
+	// Существующий клиент получает новый размер bucket'а, накопленные токены не сбрасываются
+	w := doRequest(router, http.MethodGet, "/orders", "10.0.0.1:1", nil)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+
+	// Отдельного лимита для POST /orders больше нет: его bucket удалён
+	limiter.mu.Lock()
+	assert.NotContains(t, limiter.clients, "POST /orders|10.0.0.1")
+	limiter.mu.Unlock()
+
+	assert.Error(t, limiter.SetLimits(config.RateLimiter{RPS: 1, Burst: 1, TrustedProxies: []string{"not-an-ip"}}))
+}
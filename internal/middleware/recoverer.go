@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"L0/internal/models"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Recoverer заменяет middleware.Recoverer из chi: паника пишется в slog со стеком,
+// а клиент получает 500 в общем формате ошибок вместо пустого ответа
+func Recoverer() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				// Штатный способ оборвать ответ, его нужно пробросить дальше
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+
+				slog.Error("panic in HTTP handler",
+					slog.Any("panic", rvr),
+					slog.String("method", r.Method),
+					slog.String("path", r.URL.Path),
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				if r.Header.Get("Connection") != "Upgrade" {
+					WriteError(w, r, http.StatusInternalServerError, models.ErrCodeInternal, "internal server error")
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Пути, которые опрашиваются по расписанию и только засоряли бы трассы
+var untracedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// Tracing открывает span на каждый запрос, продолжая трассу из заголовка
+// traceparent. Имя span'а - метод и шаблон маршрута chi, как у метрик:
+// шаблон известен только после роутинга, поэтому имя задаётся в конце запроса
+func Tracing() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			if route := routePattern(r); route != "" {
+				span := trace.SpanFromContext(r.Context())
+				span.SetName(spanName(r))
+				span.SetAttributes(semconv.HTTPRoute(route))
+			}
+		})
+
+		return otelhttp.NewHandler(named, "http.request",
+			// otelhttp сам переименовывает span после запроса, если задан r.Pattern
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return spanName(r) }),
+			otelhttp.WithFilter(func(r *http.Request) bool { return !untracedPaths[r.URL.Path] }),
+		)
+	}
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
+
+func spanName(r *http.Request) string {
+	if route := routePattern(r); route != "" {
+		return r.Method + " " + route
+	}
+	return r.Method
+}
@@ -0,0 +1,205 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed postgres/*.sql
+var postgresFS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFS embed.FS
+
+// Migration - пара up/down скриптов с общим номером версии
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status - состояние одной миграции в БД
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// driver выполняет миграции в конкретной СУБД.
+// apply должен выполнять скрипт и запись в schema_migrations в одной транзакции.
+type driver interface {
+	lock(ctx context.Context) (unlock func(), err error)
+	ensureTable(ctx context.Context) error
+	applied(ctx context.Context) (map[int64]time.Time, error)
+	apply(ctx context.Context, m Migration, up bool) error
+}
+
+type Migrator struct {
+	driver     driver
+	migrations []Migration
+}
+
+var fileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Load читает миграции вида 0001_name.up.sql / 0001_name.down.sql из каталога dir
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		match := fileRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse version of %s: %w", e.Name(), err)
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", e.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// Up применяет все ещё не применённые миграции по возрастанию версии
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	unlock, err := m.prepare(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.driver.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+
+		start := time.Now()
+		if err := m.driver.apply(ctx, mig, true); err != nil {
+			return count, fmt.Errorf("apply migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		slog.Info("Migration applied", "version", mig.Version, "name", mig.Name, "duration", time.Since(start).String())
+		count++
+	}
+
+	return count, nil
+}
+
+// Down откатывает steps последних применённых миграций
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	unlock, err := m.prepare(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.driver.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == "" {
+			return count, fmt.Errorf("migration %d_%s has no down script", mig.Version, mig.Name)
+		}
+
+		if err := m.driver.apply(ctx, mig, false); err != nil {
+			return count, fmt.Errorf("revert migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		slog.Info("Migration reverted", "version", mig.Version, "name", mig.Name)
+		count++
+	}
+
+	return count, nil
+}
+
+// Status возвращает состояние всех известных миграций
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.driver.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.driver.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		result = append(result, Status{
+			Version:   mig.Version,
+			Name:      mig.Name,
+			Applied:   ok,
+			AppliedAt: at,
+		})
+	}
+	return result, nil
+}
+
+// prepare берёт блокировку, чтобы несколько экземпляров сервиса не мигрировали одновременно
+func (m *Migrator) prepare(ctx context.Context) (func(), error) {
+	unlock, err := m.driver.lock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire migration lock: %w", err)
+	}
+
+	if err := m.driver.ensureTable(ctx); err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return unlock, nil
+}
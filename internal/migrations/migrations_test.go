@@ -0,0 +1,147 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// fakeDriver хранит применённые версии в памяти
+type fakeDriver struct {
+	appliedVersions map[int64]time.Time
+	calls           []string
+}
+
+func (d *fakeDriver) lock(context.Context) (func(), error) { return func() {}, nil }
+
+func (d *fakeDriver) ensureTable(context.Context) error { return nil }
+
+func (d *fakeDriver) applied(context.Context) (map[int64]time.Time, error) {
+	result := make(map[int64]time.Time, len(d.appliedVersions))
+	for v, at := range d.appliedVersions {
+		result[v] = at
+	}
+	return result, nil
+}
+
+func (d *fakeDriver) apply(_ context.Context, m Migration, up bool) error {
+	if up {
+		d.calls = append(d.calls, "up:"+m.Name)
+		d.appliedVersions[m.Version] = time.Now()
+	} else {
+		d.calls = append(d.calls, "down:"+m.Name)
+		delete(d.appliedVersions, m.Version)
+	}
+	return nil
+}
+
+func TestLoad_SortsAndPairsFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"db/0002_indexes.up.sql":   {Data: []byte("CREATE INDEX")},
+		"db/0002_indexes.down.sql": {Data: []byte("DROP INDEX")},
+		"db/0001_init.up.sql":      {Data: []byte("CREATE TABLE")},
+		"db/README.md":             {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys, "db")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "init", migrations[0].Name)
+	assert.Empty(t, migrations[0].Down)
+	assert.Equal(t, "CREATE INDEX", migrations[1].Up)
+	assert.Equal(t, "DROP INDEX", migrations[1].Down)
+}
+
+func TestLoad_MissingUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"db/0001_init.down.sql": {Data: []byte("DROP TABLE")},
+	}
+
+	_, err := Load(fsys, "db")
+	assert.Error(t, err)
+}
+
+func TestLoad_EmbeddedPostgres(t *testing.T) {
+	migrations, err := Load(postgresFS, "postgres")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, "init", migrations[0].Name)
+	assert.NotEmpty(t, migrations[0].Down)
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	driver := &fakeDriver{appliedVersions: map[int64]time.Time{1: time.Now()}}
+	m := &Migrator{
+		driver: driver,
+		migrations: []Migration{
+			{Version: 1, Name: "init", Up: "1", Down: "1"},
+			{Version: 2, Name: "second", Up: "2", Down: "2"},
+			{Version: 3, Name: "third", Up: "3", Down: "3"},
+		},
+	}
+	ctx := context.Background()
+
+	n, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"up:second", "up:third"}, driver.calls)
+
+	n, err = m.Down(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"up:second", "up:third", "down:third", "down:second"}, driver.calls)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+	assert.False(t, statuses[2].Applied)
+}
+
+func TestMigrator_SQLite_UpDown(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "orders.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := NewSQLite(db)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	total := len(m.migrations)
+
+	n, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, total, n)
+
+	// Повторный запуск ничего не применяет
+	n, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	var tables int
+	require.NoError(t, db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name IN ('orders', 'deliveries', 'payments', 'items')`).Scan(&tables))
+	assert.Equal(t, 4, tables)
+
+	n, err = m.Down(ctx, total)
+	require.NoError(t, err)
+	assert.Equal(t, total, n)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, total)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+}
@@ -0,0 +1,124 @@
+package migrations
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Произвольный ключ advisory lock для миграций
+const postgresLockKey = 740_129_001
+
+// NewPostgres возвращает мигратор со встроенными миграциями PostgreSQL
+func NewPostgres(db *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := Load(postgresFS, "postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		driver:     &postgresDriver{db: db},
+		migrations: migrations,
+	}, nil
+}
+
+// pgQuerier реализуют и *pgxpool.Pool, и *pgxpool.Conn
+type pgQuerier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type postgresDriver struct {
+	db   *pgxpool.Pool
+	conn *pgxpool.Conn
+}
+
+// q возвращает соединение с блокировкой, если она взята, иначе пул
+func (d *postgresDriver) q() pgQuerier {
+	if d.conn != nil {
+		return d.conn
+	}
+	return d.db
+}
+
+// lock держит advisory lock на отдельном соединении, миграции выполняются через него же
+func (d *postgresDriver) lock(ctx context.Context) (func(), error) {
+	conn, err := d.db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", postgresLockKey); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	d.conn = conn
+
+	return func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", postgresLockKey); err != nil {
+			slog.Error("failed to release migration lock", "error", err)
+		}
+		d.conn = nil
+		conn.Release()
+	}, nil
+}
+
+func (d *postgresDriver) ensureTable(ctx context.Context) error {
+	_, err := d.q().Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(200) NOT NULL,
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`)
+	return err
+}
+
+func (d *postgresDriver) applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.q().Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = appliedAt
+	}
+	return result, rows.Err()
+}
+
+func (d *postgresDriver) apply(ctx context.Context, m Migration, up bool) error {
+	tx, err := d.q().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			slog.Error("failed to rollback migration transaction", "error", err)
+		}
+	}()
+
+	script, record, args := m.Down, `DELETE FROM schema_migrations WHERE version = $1`, []any{m.Version}
+	if up {
+		script, record, args = m.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, []any{m.Version, m.Name}
+	}
+
+	if _, err := tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -54,3 +54,9 @@ CREATE TABLE IF NOT EXISTS items (
     brand VARCHAR(50),
     status INTEGER
 );
+
+-- Постраничный вывод списка заказов и фильтры
+CREATE INDEX IF NOT EXISTS idx_orders_date_created ON orders (date_created DESC, order_uid DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX IF NOT EXISTS idx_orders_track_number ON orders (track_number);
+CREATE INDEX IF NOT EXISTS idx_items_order_uid ON items (order_uid);
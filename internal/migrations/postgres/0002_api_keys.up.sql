@@ -0,0 +1,8 @@
+-- API-ключи для AUTH_KEYS_FROM_DB. Хранится только SHA-256 ключа в hex
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_hash CHAR(64) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'support', 'admin')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP WITH TIME ZONE -- отозванный ключ не принимается
+);
@@ -0,0 +1,10 @@
+-- Откат возможен только после расшифровки всех строк: шифротекст не помещается в старые размеры
+DROP INDEX IF EXISTS idx_deliveries_key_id;
+
+ALTER TABLE deliveries
+    DROP COLUMN IF EXISTS dek,
+    DROP COLUMN IF EXISTS key_id,
+    ALTER COLUMN name TYPE VARCHAR(100),
+    ALTER COLUMN phone TYPE VARCHAR(20),
+    ALTER COLUMN address TYPE VARCHAR(200),
+    ALTER COLUMN email TYPE VARCHAR(100);
@@ -0,0 +1,12 @@
+-- Шифрование персональных данных (PII_ENCRYPTION_KEY_FILE). Шифротекст длиннее
+-- исходных значений, поэтому колонки становятся TEXT. key_id - мастер-ключ,
+-- которым зашифрован ключ данных строки dek; NULL - строка не зашифрована
+ALTER TABLE deliveries
+    ALTER COLUMN name TYPE TEXT,
+    ALTER COLUMN phone TYPE TEXT,
+    ALTER COLUMN address TYPE TEXT,
+    ALTER COLUMN email TYPE TEXT,
+    ADD COLUMN IF NOT EXISTS key_id VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS dek BYTEA;
+
+CREATE INDEX IF NOT EXISTS idx_deliveries_key_id ON deliveries (key_id);
@@ -0,0 +1,86 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// NewSQLite возвращает мигратор со встроенными миграциями SQLite
+func NewSQLite(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load(sqliteFS, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		driver:     &sqliteDriver{db: db},
+		migrations: migrations,
+	}, nil
+}
+
+type sqliteDriver struct {
+	db *sql.DB
+}
+
+// lock ничего не блокирует: advisory lock'ов в SQLite нет. Если два процесса
+// применят одну миграцию одновременно, второй упадёт на первичном ключе
+// schema_migrations, и его транзакция вместе со скриптом откатится.
+func (d *sqliteDriver) lock(context.Context) (func(), error) {
+	return func() {}, nil
+}
+
+func (d *sqliteDriver) ensureTable(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)`)
+	return err
+}
+
+func (d *sqliteDriver) applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64]time.Time)
+	for rows.Next() {
+		var version, appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		result[version] = time.Unix(appliedAt, 0).UTC()
+	}
+	return result, rows.Err()
+}
+
+func (d *sqliteDriver) apply(ctx context.Context, m Migration, up bool) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			slog.Error("failed to rollback migration transaction", "error", err)
+		}
+	}()
+
+	script, record, args := m.Down, `DELETE FROM schema_migrations WHERE version = ?`, []any{m.Version}
+	if up {
+		script, record, args = m.Up, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`, []any{m.Version, m.Name, time.Now().Unix()}
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
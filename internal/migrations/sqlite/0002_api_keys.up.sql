@@ -0,0 +1,8 @@
+-- API-ключи для AUTH_KEYS_FROM_DB. Хранится только SHA-256 ключа в hex
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_hash TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('viewer', 'support', 'admin')),
+    created_at INTEGER NOT NULL DEFAULT (CAST(unixepoch('subsec') * 1000000000 AS INTEGER)),
+    revoked_at INTEGER -- отозванный ключ не принимается
+);
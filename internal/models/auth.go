@@ -0,0 +1,54 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// Role - роль владельца API-ключа. Роли упорядочены: каждая следующая
+// может всё, что и предыдущая
+type Role string
+
+const (
+	RoleViewer  Role = "viewer"  // чтение заказов, персональные данные скрыты маской
+	RoleSupport Role = "support" // все данные заказов и их создание
+	RoleAdmin   Role = "admin"   // служебные endpoint'ы: метрики, pprof
+)
+
+var roleLevels = map[Role]int{
+	RoleViewer:  1,
+	RoleSupport: 2,
+	RoleAdmin:   3,
+}
+
+// ParseRole проверяет, что роль известна
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := roleLevels[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Allows сообщает, достаточно ли роли r для действия, требующего роль required
+func (r Role) Allows(required Role) bool {
+	level, ok := roleLevels[r]
+	return ok && level >= roleLevels[required]
+}
+
+// APIKey - владелец ключа. Сам ключ нигде не хранится, только его хэш
+type APIKey struct {
+	Name string
+	Role Role
+}
+
+// ErrAPIKeyNotFound - ключа нет или он отозван
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+// HashAPIKey возвращает hex SHA-256 ключа, в таком виде ключи лежат в таблице api_keys
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,11 +2,32 @@ package models
 
 import "strings"
 
+// Коды ошибок JSON API. В отличие от текста сообщения, код не меняется
+// и по нему клиенты могут различать ошибки
+const (
+	ErrCodeInvalidRequest   = "invalid_request"
+	ErrCodeUnauthorized     = "unauthorized"
+	ErrCodeForbidden        = "forbidden"
+	ErrCodeValidationFailed = "validation_failed"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	ErrCodeAlreadyExists    = "already_exists"
+	ErrCodePayloadTooLarge  = "payload_too_large"
+	ErrCodeRateLimited      = "rate_limited"
+	ErrCodeUnavailable      = "unavailable"
+	ErrCodeInternal         = "internal"
+)
+
+// APIError - ошибка JSON API. RequestID совпадает с X-Request-Id в логах,
+// Details заполняется только для ошибок валидации
 type APIError struct {
-	Message string `json:"message"`
-	Code    string `json:"code"`
+	Code      string                  `json:"code" example:"not_found"`
+	Message   string                  `json:"message" example:"order not found: b563feb7b2b84b6test"`
+	RequestID string                  `json:"request_id,omitempty" example:"host/abcdef-000001"`
+	Details   []InvalidOrderDataError `json:"details,omitempty"`
 }
 
+// ErrorResponse - тело любого ответа JSON API с ошибкой
 type ErrorResponse struct {
 	Error APIError `json:"error"`
 }
@@ -19,9 +40,18 @@ func (e OrderNotFoundError) Error() string {
 	return "order not found: " + e.OrderUID
 }
 
+// OrderAlreadyExistsError - заказ с таким order_uid уже сохранён
+type OrderAlreadyExistsError struct {
+	OrderUID string
+}
+
+func (e OrderAlreadyExistsError) Error() string {
+	return "order already exists: " + e.OrderUID
+}
+
 type InvalidOrderDataError struct {
-	Field   string
-	Message string
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e InvalidOrderDataError) Error() string {
@@ -37,6 +67,10 @@ func (e DatabaseError) Error() string {
 	return "database error during " + e.Operation + ": " + e.Err.Error()
 }
 
+func (e DatabaseError) Unwrap() error {
+	return e.Err
+}
+
 type KafkaError struct {
 	Operation string
 	Err       error
@@ -46,13 +80,18 @@ func (e KafkaError) Error() string {
 	return "kafka error during " + e.Operation + ": " + e.Err.Error()
 }
 
+// ValidationError собирает все нарушения, найденные при проверке заказа
 type ValidationError struct {
-	Errors []string
+	Errors []InvalidOrderDataError
 }
 
 func (e ValidationError) Error() string {
 	if len(e.Errors) == 1 {
-		return "validation error: " + e.Errors[0]
+		return "validation error: " + e.Errors[0].Field + " - " + e.Errors[0].Message
+	}
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Field+" - "+fe.Message)
 	}
-	return "validation errors: " + strings.Join(e.Errors, "; ")
+	return "validation errors: " + strings.Join(msgs, "; ")
 }
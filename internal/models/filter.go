@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// OrderFilter описывает выборку списка заказов.
+// Пустые поля не ограничивают выборку.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Locale          string
+	Currency        string
+	CreatedFrom     time.Time // включительно
+	CreatedTo       time.Time // не включительно
+	Cursor          *OrderCursor
+	Limit           int
+}
+
+// OrderCursor указывает на последний заказ предыдущей страницы.
+// Список отсортирован по (date_created, order_uid) по убыванию.
+type OrderCursor struct {
+	DateCreated time.Time `json:"d"`
+	OrderUID    string    `json:"u"`
+}
+
+// OrderPage - страница списка заказов. NextCursor пуст на последней странице.
+type OrderPage struct {
+	Orders     []Order
+	NextCursor *OrderCursor
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidLimit - в фильтре не задан размер страницы
+var ErrInvalidLimit = errors.New("limit must be positive")
+
+// CursorAfter возвращает курсор, указывающий на заказ
+func CursorAfter(order Order) *OrderCursor {
+	return &OrderCursor{DateCreated: order.DateCreated, OrderUID: order.OrderUID}
+}
+
+// Encode возвращает непрозрачное для клиента представление курсора
+func (c OrderCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeOrderCursor(s string) (OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return OrderCursor{}, ErrInvalidCursor
+	}
+
+	var c OrderCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.OrderUID == "" {
+		return OrderCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// EventOrderStored - заказ сохранён в БД (транзакция закоммичена)
+const EventOrderStored = "order.stored"
+
+// OrderStoredEvent - тело события order.stored. Персональных данных
+// покупателя в событии нет: они остаются только в БД
+type OrderStoredEvent struct {
+	Event       string    `json:"event"`
+	OrderUID    string    `json:"order_uid"`
+	TrackNumber string    `json:"track_number"`
+	Amount      int       `json:"amount"`
+	Currency    string    `json:"currency"`
+	ItemsCount  int       `json:"items_count"`
+	DateCreated time.Time `json:"date_created"`
+	StoredAt    time.Time `json:"stored_at"`
+}
+
+// NewOrderStoredEvent собирает событие о сохранении заказа
+func NewOrderStoredEvent(order Order, storedAt time.Time) OrderStoredEvent {
+	return OrderStoredEvent{
+		Event:       EventOrderStored,
+		OrderUID:    order.OrderUID,
+		TrackNumber: order.TrackNumber,
+		Amount:      order.Payment.Amount,
+		Currency:    order.Payment.Currency,
+		ItemsCount:  len(order.Items),
+		DateCreated: order.DateCreated,
+		StoredAt:    storedAt,
+	}
+}
+
+// OutboxMessage - неотправленная запись transactional outbox
+type OutboxMessage struct {
+	ID        int64
+	EventType string
+	Key       string // ключ сообщения в Kafka, order_uid
+	Payload   []byte
+	CreatedAt time.Time
+}
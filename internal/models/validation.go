@@ -0,0 +1,93 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// validator накапливает нарушения, чтобы вернуть их все одной ошибкой
+type validator struct {
+	errs []InvalidOrderDataError
+}
+
+func (v *validator) add(field, message string) {
+	v.errs = append(v.errs, InvalidOrderDataError{Field: field, Message: message})
+}
+
+func (v *validator) required(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, "is required")
+	}
+}
+
+func (v *validator) nonNegative(field string, value int) {
+	if value < 0 {
+		v.add(field, "must not be negative")
+	}
+}
+
+// Validate проверяет заказ перед сохранением.
+// Возвращает ValidationError со всеми найденными нарушениями или nil.
+func (o Order) Validate() error {
+	var v validator
+
+	v.required("order_uid", o.OrderUID)
+	v.required("track_number", o.TrackNumber)
+	v.required("entry", o.Entry)
+	v.required("customer_id", o.CustomerID)
+	if o.DateCreated.IsZero() {
+		v.add("date_created", "is required")
+	}
+
+	v.required("delivery.name", o.Delivery.Name)
+	v.required("delivery.phone", o.Delivery.Phone)
+	v.required("delivery.city", o.Delivery.City)
+	v.required("delivery.address", o.Delivery.Address)
+
+	v.required("payment.transaction", o.Payment.Transaction)
+	v.required("payment.currency", o.Payment.Currency)
+	v.required("payment.provider", o.Payment.Provider)
+	if o.Payment.Transaction != "" && o.OrderUID != "" && o.Payment.Transaction != o.OrderUID {
+		v.add("payment.transaction", "must match order_uid")
+	}
+	v.nonNegative("payment.amount", o.Payment.Amount)
+	v.nonNegative("payment.delivery_cost", o.Payment.DeliveryCost)
+	v.nonNegative("payment.goods_total", o.Payment.GoodsTotal)
+	v.nonNegative("payment.custom_fee", o.Payment.CustomFee)
+
+	if len(o.Items) == 0 {
+		v.add("items", "must contain at least one item")
+	}
+	for i, item := range o.Items {
+		prefix := fmt.Sprintf("items[%d].", i)
+		v.required(prefix+"name", item.Name)
+		if item.TrackNumber != o.TrackNumber {
+			v.add(prefix+"track_number", "must match order track_number")
+		}
+		v.nonNegative(prefix+"price", item.Price)
+		v.nonNegative(prefix+"sale", item.Sale)
+		v.nonNegative(prefix+"total_price", item.TotalPrice)
+	}
+
+	if len(v.errs) > 0 {
+		return ValidationError{Errors: v.errs}
+	}
+	return nil
+}
+
+// DecodeOrder разбирает JSON заказа и валидирует его. Используется везде,
+// где заказ приходит извне, чтобы правила приёма были одинаковыми.
+// Невалидный заказ возвращается вместе с ошибкой, чтобы его можно было залогировать.
+func DecodeOrder(data []byte) (Order, error) {
+	var order Order
+	if err := json.Unmarshal(data, &order); err != nil {
+		return Order{}, err
+	}
+
+	if err := order.Validate(); err != nil {
+		return order, err
+	}
+
+	return order, nil
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validOrder() Order {
+	return Order{
+		OrderUID:    "test-uid",
+		TrackNumber: "TRACK123",
+		Entry:       "WBIL",
+		CustomerID:  "customer-1",
+		DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		Delivery: Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+		},
+		Payment: Payment{
+			Transaction: "test-uid",
+			Currency:    "USD",
+			Provider:    "wbpay",
+			Amount:      1817,
+		},
+		Items: []Item{
+			{ChrtID: 1, TrackNumber: "TRACK123", Name: "Mascaras", Price: 453, TotalPrice: 317},
+		},
+	}
+}
+
+func TestOrder_Validate_Valid(t *testing.T) {
+	assert.NoError(t, validOrder().Validate())
+}
+
+func TestOrder_Validate_CollectsAllErrors(t *testing.T) {
+	order := validOrder()
+	order.CustomerID = ""
+	order.Payment.Amount = -1
+	order.Payment.Transaction = "other-uid"
+	order.Items[0].TrackNumber = "OTHER"
+
+	err := order.Validate()
+	require.Error(t, err)
+
+	var validationErr ValidationError
+	require.True(t, errors.As(err, &validationErr))
+
+	fields := make([]string, 0, len(validationErr.Errors))
+	for _, fe := range validationErr.Errors {
+		fields = append(fields, fe.Field)
+	}
+	assert.ElementsMatch(t, []string{
+		"customer_id",
+		"payment.amount",
+		"payment.transaction",
+		"items[0].track_number",
+	}, fields)
+}
+
+func TestOrder_Validate_EmptyItems(t *testing.T) {
+	order := validOrder()
+	order.Items = nil
+
+	err := order.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "items - must contain at least one item")
+}
+
+func TestDecodeOrder_TestData(t *testing.T) {
+	tests := []struct {
+		file    string
+		wantErr bool
+		invalid bool
+	}{
+		{file: "valid-order-template.json"},
+		{file: "error-negative-amount.json", wantErr: true, invalid: true},
+		{file: "error-empty-items.json", wantErr: true, invalid: true},
+		{file: "error-missing-uid.json", wantErr: true, invalid: true},
+		{file: "error-wrong-type.json", wantErr: true},
+		{file: "error-invalid-syntax.json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("..", "..", "testdata", tt.file))
+			require.NoError(t, err)
+
+			_, err = DecodeOrder(data)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			var validationErr ValidationError
+			assert.Equal(t, tt.invalid, errors.As(err, &validationErr))
+		})
+	}
+}
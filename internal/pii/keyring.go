@@ -0,0 +1,224 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Конвертное шифрование: у каждой строки свой ключ данных (DEK), которым
+// шифруются её поля. DEK хранится рядом со строкой, зашифрованный мастер-ключом
+// (KEK) из файла. При ротации в файл добавляется новый KEK и объявляется
+// основным: новые строки шифруются им, старые читаются по key_id, а Rewrap
+// перешифровывает только DEK, не трогая сами поля
+
+// encryptedPrefix отличает шифротекст от открытых значений, записанных до
+// включения шифрования
+const encryptedPrefix = "enc:v1:"
+
+const keySize = 32 // AES-256
+
+// ErrUnknownKey - строка зашифрована ключом, которого нет в файле ключей
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// keyFile - формат файла ключей:
+//
+//	{"primary": "2025-01", "keys": {"2024-06": "<base64>", "2025-01": "<base64>"}}
+type keyFile struct {
+	Primary string            `json:"primary"`
+	Keys    map[string]string `json:"keys"`
+}
+
+// Keyring - мастер-ключи из файла. Основной ключ шифрует новые DEK,
+// остальные нужны, чтобы читать строки до их перешифровки
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// LoadKeyring читает файл ключей. Ключи - 32 байта в base64,
+// например из `openssl rand -base64 32`
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse key file: %w", err)
+	}
+
+	keys := make(map[string][]byte, len(file.Keys))
+	for id, encoded := range file.Keys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+	return NewKeyring(file.Primary, keys)
+}
+
+// NewKeyring проверяет ключи и готовит их к работе
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || len(id) > 50 {
+			return nil, fmt.Errorf("key id %q must be 1-50 characters long", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+	}
+	if _, ok := k.keys[primary]; !ok {
+		return nil, fmt.Errorf("primary key %q is not in the key file", primary)
+	}
+	return k, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Primary - id ключа, которым шифруются новые строки
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// Envelope - ключ данных одной строки. scope (order_uid) входит в
+// associated data, поэтому шифротекст нельзя перенести в другую строку
+type Envelope struct {
+	KeyID      string
+	WrappedDEK []byte
+
+	scope string
+	aead  cipher.AEAD
+}
+
+// NewEnvelope создаёт новый DEK и шифрует его основным ключом
+func (k *Keyring) NewEnvelope(scope string) (Envelope, error) {
+	dek := make([]byte, keySize)
+	if _, err := rand.Read(dek); err != nil {
+		return Envelope{}, fmt.Errorf("generate data key: %w", err)
+	}
+
+	wrapped, err := seal(k.keys[k.primary], dek, []byte(scope))
+	if err != nil {
+		return Envelope{}, err
+	}
+	return k.envelope(k.primary, wrapped, dek, scope)
+}
+
+// OpenEnvelope расшифровывает DEK строки ключом keyID
+func (k *Keyring) OpenEnvelope(keyID string, wrapped []byte, scope string) (Envelope, error) {
+	dek, err := k.unwrap(keyID, wrapped, scope)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return k.envelope(keyID, wrapped, dek, scope)
+}
+
+// Rewrap перешифровывает DEK строки основным ключом. Поля строки остаются как есть
+func (k *Keyring) Rewrap(keyID string, wrapped []byte, scope string) (Envelope, error) {
+	dek, err := k.unwrap(keyID, wrapped, scope)
+	if err != nil {
+		return Envelope{}, err
+	}
+	if keyID != k.primary {
+		if wrapped, err = seal(k.keys[k.primary], dek, []byte(scope)); err != nil {
+			return Envelope{}, err
+		}
+	}
+	return k.envelope(k.primary, wrapped, dek, scope)
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte, scope string) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	dek, err := open(kek, wrapped, []byte(scope))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	return dek, nil
+}
+
+func (k *Keyring) envelope(keyID string, wrapped, dek []byte, scope string) (Envelope, error) {
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{KeyID: keyID, WrappedDEK: wrapped, scope: scope, aead: aead}, nil
+}
+
+// Encrypt шифрует значение поля field. Пустые значения не шифруются
+func (e Envelope) Encrypt(field, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	sealed, err := seal(e.aead, []byte(value), e.ad(field))
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение поля field. Значения без префикса
+// шифротекста возвращаются как есть: они записаны до включения шифрования
+func (e Envelope) Decrypt(field, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", field, err)
+	}
+	plain, err := open(e.aead, sealed, e.ad(field))
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", field, err)
+	}
+	return string(plain), nil
+}
+
+func (e Envelope) ad(field string) []byte {
+	return []byte(e.scope + "\x00" + field)
+}
+
+// IsEncrypted сообщает, что значение записано через Envelope.Encrypt
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// seal возвращает nonce || шифротекст
+func seal(aead cipher.AEAD, plain, ad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plain, ad), nil
+}
+
+func open(aead cipher.AEAD, sealed, ad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, ad)
+}
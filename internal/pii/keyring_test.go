@@ -0,0 +1,115 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	k, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	env, err := k.NewEnvelope("order-1")
+	require.NoError(t, err)
+	assert.Equal(t, "k1", env.KeyID)
+
+	encrypted, err := env.Encrypt("phone", "+9720000000")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.NotContains(t, encrypted, "9720000000")
+
+	opened, err := k.OpenEnvelope(env.KeyID, env.WrappedDEK, "order-1")
+	require.NoError(t, err)
+	plain, err := opened.Decrypt("phone", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "+9720000000", plain)
+
+	// Открытые значения, записанные до включения шифрования, читаются как есть
+	plain, err = opened.Decrypt("phone", "+9721111111")
+	require.NoError(t, err)
+	assert.Equal(t, "+9721111111", plain)
+
+	empty, err := env.Encrypt("email", "")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestEnvelope_BoundToRowAndField(t *testing.T) {
+	k, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	env, err := k.NewEnvelope("order-1")
+	require.NoError(t, err)
+	encrypted, err := env.Encrypt("phone", "+9720000000")
+	require.NoError(t, err)
+
+	_, err = env.Decrypt("email", encrypted)
+	assert.Error(t, err, "value moved to another column")
+
+	_, err = k.OpenEnvelope(env.KeyID, env.WrappedDEK, "order-2")
+	assert.Error(t, err, "data key moved to another row")
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, err := NewKeyring("k1", map[string][]byte{"k1": testKey(1)})
+	require.NoError(t, err)
+
+	env, err := old.NewEnvelope("order-1")
+	require.NoError(t, err)
+	encrypted, err := env.Encrypt("name", "Test Testov")
+	require.NoError(t, err)
+
+	rotated, err := NewKeyring("k2", map[string][]byte{"k1": testKey(1), "k2": testKey(2)})
+	require.NoError(t, err)
+
+	rewrapped, err := rotated.Rewrap(env.KeyID, env.WrappedDEK, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rewrapped.KeyID)
+	assert.NotEqual(t, env.WrappedDEK, rewrapped.WrappedDEK)
+
+	// После перешифровки старый ключ можно убрать из файла
+	onlyNew, err := NewKeyring("k2", map[string][]byte{"k2": testKey(2)})
+	require.NoError(t, err)
+
+	opened, err := onlyNew.OpenEnvelope(rewrapped.KeyID, rewrapped.WrappedDEK, "order-1")
+	require.NoError(t, err)
+	plain, err := opened.Decrypt("name", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "Test Testov", plain)
+
+	_, err = onlyNew.OpenEnvelope(env.KeyID, env.WrappedDEK, "order-1")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	key := base64.StdEncoding.EncodeToString(testKey(7))
+
+	k, err := LoadKeyring(write("ok.json", `{"primary": "2025-01", "keys": {"2025-01": "`+key+`"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01", k.Primary())
+
+	_, err = LoadKeyring(write("no-primary.json", `{"primary": "2025-02", "keys": {"2025-01": "`+key+`"}}`))
+	assert.Error(t, err)
+
+	_, err = LoadKeyring(write("short.json", `{"primary": "a", "keys": {"a": "c2hvcnQ="}}`))
+	assert.Error(t, err)
+
+	_, err = LoadKeyring(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+// Package pii скрывает и шифрует персональные данные покупателей:
+// маскирование при отдаче заказа клиенту API и конвертное шифрование
+// колонок deliveries в PostgreSQL
+package pii
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"L0/internal/models"
+)
+
+// DefaultMaskRules используются, если PII_MASK_RULES не задан
+const DefaultMaskRules = "delivery.name=partial:1:0,delivery.phone=partial:4:4,delivery.zip=full,delivery.address=full," +
+	"delivery.email=email,payment.transaction=partial:0:4,payment.request_id=full"
+
+const maskFill = "***"
+
+// maskFields - поля заказа, которые можно маскировать, по имени в правилах
+var maskFields = map[string]func(o *models.Order) *string{
+	"customer_id":         func(o *models.Order) *string { return &o.CustomerID },
+	"delivery.name":       func(o *models.Order) *string { return &o.Delivery.Name },
+	"delivery.phone":      func(o *models.Order) *string { return &o.Delivery.Phone },
+	"delivery.zip":        func(o *models.Order) *string { return &o.Delivery.Zip },
+	"delivery.city":       func(o *models.Order) *string { return &o.Delivery.City },
+	"delivery.address":    func(o *models.Order) *string { return &o.Delivery.Address },
+	"delivery.region":     func(o *models.Order) *string { return &o.Delivery.Region },
+	"delivery.email":      func(o *models.Order) *string { return &o.Delivery.Email },
+	"payment.transaction": func(o *models.Order) *string { return &o.Payment.Transaction },
+	"payment.request_id":  func(o *models.Order) *string { return &o.Payment.RequestID },
+	"payment.provider":    func(o *models.Order) *string { return &o.Payment.Provider },
+	"payment.bank":        func(o *models.Order) *string { return &o.Payment.Bank },
+}
+
+type maskRule struct {
+	field    func(o *models.Order) *string
+	mask     func(string) string
+	unmasked models.Role // роль, начиная с которой поле видно как есть
+}
+
+// Masker применяет правила маскирования к заказу в зависимости от роли вызывающего
+type Masker struct {
+	rules []maskRule
+}
+
+// NewMasker разбирает правила вида "field[@role]=strategy" через запятую.
+// Стратегии: full - значение целиком заменяется на ***, partial:S:E - остаются
+// S первых и E последних символов, email - первый символ имени и домен.
+// Роль в правиле - с какой роли поле видно без маски, по умолчанию unmaskedRole.
+// Пустые rules - DefaultMaskRules
+func NewMasker(rules, unmaskedRole string) (*Masker, error) {
+	defaultRole, err := models.ParseRole(unmaskedRole)
+	if err != nil {
+		return nil, fmt.Errorf("unmasked role: %w", err)
+	}
+	if strings.TrimSpace(rules) == "" {
+		rules = DefaultMaskRules
+	}
+
+	m := &Masker{}
+	for _, entry := range strings.Split(rules, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		rule, err := parseMaskRule(entry, defaultRole)
+		if err != nil {
+			return nil, fmt.Errorf("mask rule %q: %w", entry, err)
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+func parseMaskRule(entry string, defaultRole models.Role) (maskRule, error) {
+	target, strategy, ok := strings.Cut(entry, "=")
+	if !ok {
+		return maskRule{}, fmt.Errorf(`expected "field[@role]=strategy"`)
+	}
+
+	rule := maskRule{unmasked: defaultRole}
+
+	name, roleName, hasRole := strings.Cut(strings.TrimSpace(target), "@")
+	if hasRole {
+		role, err := models.ParseRole(roleName)
+		if err != nil {
+			return maskRule{}, err
+		}
+		rule.unmasked = role
+	}
+
+	field, ok := maskFields[name]
+	if !ok {
+		return maskRule{}, fmt.Errorf("unknown field %q, known fields: %s", name, strings.Join(knownMaskFields(), ", "))
+	}
+	rule.field = field
+
+	mask, err := parseMaskStrategy(strings.TrimSpace(strategy))
+	if err != nil {
+		return maskRule{}, err
+	}
+	rule.mask = mask
+
+	return rule, nil
+}
+
+func parseMaskStrategy(s string) (func(string) string, error) {
+	parts := strings.Split(s, ":")
+	switch parts[0] {
+	case "full":
+		if len(parts) == 1 {
+			return func(v string) string { return MaskMiddle(v, 0, 0) }, nil
+		}
+	case "email":
+		if len(parts) == 1 {
+			return MaskEmail, nil
+		}
+	case "partial":
+		if len(parts) == 3 {
+			start, errStart := strconv.Atoi(parts[1])
+			end, errEnd := strconv.Atoi(parts[2])
+			if errStart != nil || errEnd != nil || start < 0 || end < 0 {
+				return nil, fmt.Errorf("partial strategy expects non-negative numbers: %q", s)
+			}
+			return func(v string) string { return MaskMiddle(v, start, end) }, nil
+		}
+	default:
+		return nil, fmt.Errorf("unknown strategy %q, expected full, email or partial:S:E", s)
+	}
+	return nil, fmt.Errorf("malformed strategy %q", s)
+}
+
+func knownMaskFields() []string {
+	names := make([]string, 0, len(maskFields))
+	for name := range maskFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Mask возвращает копию заказа, в которой скрыты поля, недоступные роли role
+func (m *Masker) Mask(order models.Order, role models.Role) models.Order {
+	for _, rule := range m.rules {
+		if role.Allows(rule.unmasked) {
+			continue
+		}
+		value := rule.field(&order)
+		*value = rule.mask(*value)
+	}
+	return order
+}
+
+// MaskMiddle заменяет середину строки на ***, оставляя keepStart символов
+// в начале и keepEnd в конце. Короткие значения скрываются целиком
+func MaskMiddle(s string, keepStart, keepEnd int) string {
+	if s == "" {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= keepStart+keepEnd {
+		return maskFill
+	}
+	return string(runes[:keepStart]) + maskFill + string(runes[len(runes)-keepEnd:])
+}
+
+// MaskEmail оставляет первый символ имени и домен целиком
+func MaskEmail(s string) string {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok {
+		return MaskMiddle(s, 1, 0)
+	}
+	return MaskMiddle(local, 1, 0) + "@" + domain
+}
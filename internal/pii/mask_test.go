@@ -0,0 +1,103 @@
+package pii
+
+import (
+	"testing"
+
+	"L0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOrder() models.Order {
+	return models.Order{
+		OrderUID:   "b563feb7b2b84b6test",
+		CustomerID: "test",
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction: "b563feb7b2b84b6test",
+			RequestID:   "req-1",
+			Amount:      1817,
+		},
+	}
+}
+
+func TestMasker_DefaultRules(t *testing.T) {
+	m, err := NewMasker("", "support")
+	require.NoError(t, err)
+
+	order := testOrder()
+	masked := m.Mask(order, models.RoleViewer)
+
+	assert.Equal(t, "T***", masked.Delivery.Name)
+	assert.Equal(t, "+972***0000", masked.Delivery.Phone)
+	assert.Equal(t, "***", masked.Delivery.Zip)
+	assert.Equal(t, "***", masked.Delivery.Address)
+	assert.Equal(t, "t***@gmail.com", masked.Delivery.Email)
+	assert.Equal(t, "***test", masked.Payment.Transaction)
+	assert.Equal(t, "***", masked.Payment.RequestID)
+
+	// Поля без правил не меняются, исходный заказ тоже
+	assert.Equal(t, "Kiryat Mozkin", masked.Delivery.City)
+	assert.Equal(t, 1817, masked.Payment.Amount)
+	assert.Equal(t, "+9720000000", order.Delivery.Phone)
+
+	assert.Equal(t, order, m.Mask(order, models.RoleSupport))
+	assert.Equal(t, order, m.Mask(order, models.RoleAdmin))
+}
+
+func TestMasker_RuleRoles(t *testing.T) {
+	m, err := NewMasker("delivery.phone@admin=partial:4:4, customer_id=full", "support")
+	require.NoError(t, err)
+
+	order := testOrder()
+
+	support := m.Mask(order, models.RoleSupport)
+	assert.Equal(t, "+972***0000", support.Delivery.Phone)
+	assert.Equal(t, "test", support.CustomerID)
+	assert.Equal(t, "Test Testov", support.Delivery.Name, "fields without rules are not masked")
+
+	viewer := m.Mask(order, models.RoleViewer)
+	assert.Equal(t, "***", viewer.CustomerID)
+
+	assert.Equal(t, order, m.Mask(order, models.RoleAdmin))
+}
+
+func TestNewMasker_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		role  string
+	}{
+		{name: "unknown field", rules: "delivery.passport=full", role: "support"},
+		{name: "unknown strategy", rules: "delivery.phone=hash", role: "support"},
+		{name: "malformed partial", rules: "delivery.phone=partial:4", role: "support"},
+		{name: "negative partial", rules: "delivery.phone=partial:-1:2", role: "support"},
+		{name: "missing strategy", rules: "delivery.phone", role: "support"},
+		{name: "unknown rule role", rules: "delivery.phone@root=full", role: "support"},
+		{name: "unknown unmasked role", rules: "", role: "root"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMasker(tt.rules, tt.role)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMaskMiddle(t *testing.T) {
+	assert.Equal(t, "", MaskMiddle("", 1, 1))
+	assert.Equal(t, "***", MaskMiddle("ab", 1, 1))
+	assert.Equal(t, "a***c", MaskMiddle("abc", 1, 1))
+	assert.Equal(t, "Ж***", MaskMiddle("Жанна", 1, 0))
+	assert.Equal(t, "n***", MaskEmail("not-an-email"))
+}
@@ -0,0 +1,167 @@
+package memory
+
+import (
+    "context"
+    "sort"
+    "sync"
+    "time"
+
+    "L0/internal/models"
+)
+
+// Repository хранит заказы в памяти процесса. Подходит для тестов и
+// локального запуска без PostgreSQL; данные теряются при рестарте.
+type Repository struct {
+    mu     sync.RWMutex
+    orders map[string]models.Order
+}
+
+func New() *Repository {
+    return &Repository{orders: make(map[string]models.Order)}
+}
+
+func (r *Repository) Create(ctx context.Context, order models.Order) error {
+    if err := ctx.Err(); err != nil {
+        return err
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    return r.create(order)
+}
+
+// CreateBatch сохраняет заказы по одному; ошибка одного заказа не влияет на остальные
+func (r *Repository) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    results := make([]error, len(orders))
+    for i, order := range orders {
+        results[i] = r.create(order)
+    }
+    return results, nil
+}
+
+func (r *Repository) create(order models.Order) error {
+    if _, ok := r.orders[order.OrderUID]; ok {
+        return models.OrderAlreadyExistsError{OrderUID: order.OrderUID}
+    }
+    r.orders[order.OrderUID] = cloneOrder(order)
+    return nil
+}
+
+func (r *Repository) GetByUID(ctx context.Context, uid string) (models.Order, error) {
+    if err := ctx.Err(); err != nil {
+        return models.Order{}, err
+    }
+
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    order, ok := r.orders[uid]
+    if !ok {
+        return models.Order{}, models.OrderNotFoundError{OrderUID: uid}
+    }
+    return cloneOrder(order), nil
+}
+
+func (r *Repository) GetLatest(ctx context.Context, limit int) ([]models.Order, error) {
+    if err := ctx.Err(); err != nil {
+        return nil, err
+    }
+
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    return r.sorted(limit, func(models.Order) bool { return true }), nil
+}
+
+func (r *Repository) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    if err := ctx.Err(); err != nil {
+        return models.OrderPage{}, err
+    }
+    if filter.Limit < 1 {
+        return models.OrderPage{}, models.ErrInvalidLimit
+    }
+
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    // Берём на одну запись больше, чтобы понять, есть ли следующая страница
+    result := r.sorted(filter.Limit+1, func(o models.Order) bool { return matches(o, filter) })
+
+    page := models.OrderPage{Orders: result}
+    if len(result) > filter.Limit {
+        page.Orders = result[:filter.Limit]
+        page.NextCursor = models.CursorAfter(page.Orders[len(page.Orders)-1])
+    }
+    return page, nil
+}
+
+// sorted возвращает копии подходящих заказов от новых к старым, не больше limit
+func (r *Repository) sorted(limit int, keep func(models.Order) bool) []models.Order {
+    orders := []models.Order{}
+    for _, order := range r.orders {
+        if keep(order) {
+            orders = append(orders, order)
+        }
+    }
+
+    sort.Slice(orders, func(i, j int) bool {
+        return newer(orders[i], orders[j].DateCreated, orders[j].OrderUID)
+    })
+
+    if limit >= 0 && len(orders) > limit {
+        orders = orders[:limit]
+    }
+    for i := range orders {
+        orders[i] = cloneOrder(orders[i])
+    }
+    return orders
+}
+
+// newer повторяет сортировку postgres: date_created DESC, order_uid DESC
+func newer(o models.Order, dateCreated time.Time, orderUID string) bool {
+    if !o.DateCreated.Equal(dateCreated) {
+        return o.DateCreated.After(dateCreated)
+    }
+    return o.OrderUID > orderUID
+}
+
+func matches(o models.Order, filter models.OrderFilter) bool {
+    switch {
+    case filter.CustomerID != "" && o.CustomerID != filter.CustomerID:
+        return false
+    case filter.TrackNumber != "" && o.TrackNumber != filter.TrackNumber:
+        return false
+    case filter.DeliveryService != "" && o.DeliveryService != filter.DeliveryService:
+        return false
+    case filter.Locale != "" && o.Locale != filter.Locale:
+        return false
+    case filter.Currency != "" && o.Payment.Currency != filter.Currency:
+        return false
+    case !filter.CreatedFrom.IsZero() && o.DateCreated.Before(filter.CreatedFrom):
+        return false
+    case !filter.CreatedTo.IsZero() && !o.DateCreated.Before(filter.CreatedTo):
+        return false
+    case filter.Cursor != nil && newer(o, filter.Cursor.DateCreated, filter.Cursor.OrderUID):
+        return false
+    case filter.Cursor != nil && o.DateCreated.Equal(filter.Cursor.DateCreated) && o.OrderUID == filter.Cursor.OrderUID:
+        return false
+    }
+    return true
+}
+
+// cloneOrder копирует заказ вместе со слайсом товаров, чтобы вызывающий
+// код не мог изменить сохранённое состояние
+func cloneOrder(order models.Order) models.Order {
+    if order.Items != nil {
+        order.Items = append([]models.Item(nil), order.Items...)
+    }
+    return order
+}
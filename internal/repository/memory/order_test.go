@@ -0,0 +1,14 @@
+package memory
+
+import (
+    "testing"
+
+    "L0/internal/repository"
+    "L0/internal/repository/repotest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+    repotest.Run(t, func(t *testing.T) repository.OrderRepository {
+        return New()
+    })
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+)
+
+// GetAPIKey ищет неотозванный ключ по хэшу. Без повторов: запрос идёт на
+// каждый новый ключ в запросе клиента, и клиенту проще повторить самому
+func (r *Repository) GetAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+    const op = "repository.postgres.GetAPIKey"
+
+    var key models.APIKey
+    start := time.Now()
+    err := r.db.QueryRow(ctx, `SELECT name, role FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`, keyHash).
+        Scan(&key.Name, &key.Role)
+    metrics.DBOperationDuration.WithLabelValues("get_api_key", metrics.Status(err)).Observe(time.Since(start).Seconds())
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return models.APIKey{}, models.ErrAPIKeyNotFound
+    }
+    if err != nil {
+        return models.APIKey{}, models.DatabaseError{Operation: op, Err: err}
+    }
+    return key, nil
+}
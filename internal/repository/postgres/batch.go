@@ -0,0 +1,146 @@
+package postgres
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/cenkalti/backoff/v4"
+    "github.com/jackc/pgx/v5"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+)
+
+// CreateBatch сохраняет пачку заказов в одной транзакции.
+// Возвращает ошибку для каждого заказа (nil - сохранён): дубликаты получают
+// OrderAlreadyExistsError и не мешают сохранению остальных. Вторая ошибка
+// означает, что не сохранён ни один заказ.
+func (r *Repository) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    const op = "repository.postgres.CreateBatch"
+
+    if len(orders) == 0 {
+        return nil, nil
+    }
+
+    var results []error
+    operation := func() error {
+        res, err := r.createBatch(ctx, orders)
+        if err != nil {
+            if isPermanent(err) {
+                return backoff.Permanent(err)
+            }
+            metrics.DBRetries.WithLabelValues("create_batch").Inc()
+            slog.WarnContext(ctx, "Database batch operation failed, retrying...", "error", err)
+            return err
+        }
+        results = res
+        return nil
+    }
+
+    bo := backoff.NewExponentialBackOff()
+    bo.MaxElapsedTime = r.config.Retry.MaxElapsedTimeDB
+    bo.InitialInterval = r.config.Retry.InitialInterval
+    bo.MaxInterval = r.config.Retry.MaxIntervalDB
+
+    start := time.Now()
+    err := backoff.Retry(operation, backoff.WithContext(bo, ctx))
+    metrics.DBOperationDuration.WithLabelValues("create_batch", metrics.Status(err)).Observe(time.Since(start).Seconds())
+
+    if err != nil && isPermanent(err) && ctx.Err() == nil {
+        // Какой заказ нарушил ограничение, из ошибки пачки не понять - сохраняем по одному
+        slog.WarnContext(ctx, "Batch insert failed, falling back to single inserts", "error", err, "orders", len(orders))
+        results = make([]error, len(orders))
+        for i, order := range orders {
+            results[i] = r.Create(ctx, order)
+        }
+        return results, nil
+    }
+    if err != nil {
+        return nil, classifyError(op, "", err)
+    }
+
+    return results, nil
+}
+
+func (r *Repository) createBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    results := make([]error, len(orders))
+
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer func() {
+        if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+            slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
+        }
+    }()
+
+    // Заказы: дубликаты (в БД и внутри пачки) пропускаются через ON CONFLICT
+    orderBatch := &pgx.Batch{}
+    for _, order := range orders {
+        orderBatch.Queue(orderSQL+" ON CONFLICT (order_uid) DO NOTHING", orderArgs(order)...)
+    }
+
+    inserted := make([]int, 0, len(orders))
+    br := tx.SendBatch(ctx, orderBatch)
+    for i, order := range orders {
+        tag, err := br.Exec()
+        if err != nil {
+            _ = br.Close()
+            return nil, fmt.Errorf("insert order %s: %w", order.OrderUID, err)
+        }
+        if tag.RowsAffected() == 0 {
+            results[i] = models.OrderAlreadyExistsError{OrderUID: order.OrderUID}
+            continue
+        }
+        inserted = append(inserted, i)
+    }
+    if err := br.Close(); err != nil {
+        return nil, fmt.Errorf("insert orders: %w", err)
+    }
+
+    if len(inserted) == 0 {
+        return results, tx.Commit(ctx)
+    }
+
+    // Доставка, оплата и события outbox одной пачкой, товары через COPY
+    detailsBatch := &pgx.Batch{}
+    var itemRows [][]any
+    for _, i := range inserted {
+        order := orders[i]
+        delivery, err := r.deliveryArgs(order)
+        if err != nil {
+            return nil, err
+        }
+        detailsBatch.Queue(deliverySQL, delivery...)
+        detailsBatch.Queue(paymentSQL, paymentArgs(order)...)
+        if r.config.Outbox.Enabled {
+            event, err := outboxArgs(order)
+            if err != nil {
+                return nil, err
+            }
+            detailsBatch.Queue(outboxSQL, event...)
+        }
+        for _, item := range order.Items {
+            itemRows = append(itemRows, itemArgs(order.OrderUID, item))
+        }
+    }
+
+    if err := tx.SendBatch(ctx, detailsBatch).Close(); err != nil {
+        return nil, fmt.Errorf("insert deliveries, payments and outbox events: %w", err)
+    }
+
+    if len(itemRows) > 0 {
+        if _, err := tx.CopyFrom(ctx, pgx.Identifier{"items"}, itemColumns, pgx.CopyFromRows(itemRows)); err != nil {
+            return nil, fmt.Errorf("copy items: %w", err)
+        }
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return nil, fmt.Errorf("failed to commit transaction: %w", err)
+    }
+
+    return results, nil
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+    "errors"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+
+    "L0/internal/models"
+)
+
+// SQLSTATE коды, которые нужно различать
+const uniqueViolation = "23505"
+
+// classifyError переводит ошибку pgx в типизированную ошибку из models
+func classifyError(op, orderUID string, err error) error {
+    if err == nil {
+        return nil
+    }
+
+    if errors.Is(err, pgx.ErrNoRows) {
+        return models.OrderNotFoundError{OrderUID: orderUID}
+    }
+
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+        return models.OrderAlreadyExistsError{OrderUID: orderUID}
+    }
+
+    // Уже классифицированные ошибки не оборачиваем повторно
+    var notFound models.OrderNotFoundError
+    var exists models.OrderAlreadyExistsError
+    var dbErr models.DatabaseError
+    if errors.As(err, &notFound) || errors.As(err, &exists) || errors.As(err, &dbErr) {
+        return err
+    }
+
+    return models.DatabaseError{Operation: op, Err: err}
+}
+
+// isPermanent сообщает, что повтор операции не поможет:
+// заказ не найден, дубликат или ошибка данных/ограничений (классы SQLSTATE 22 и 23)
+func isPermanent(err error) bool {
+    var notFound models.OrderNotFoundError
+    var exists models.OrderAlreadyExistsError
+    if errors.As(err, &notFound) || errors.As(err, &exists) {
+        return true
+    }
+
+    var pgErr *pgconn.PgError
+    if errors.As(err, &pgErr) && len(pgErr.Code) >= 2 {
+        class := pgErr.Code[:2]
+        return class == "22" || class == "23"
+    }
+
+    return false
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+    "errors"
+    "fmt"
+    "testing"
+
+    "github.com/jackc/pgx/v5"
+    "github.com/jackc/pgx/v5/pgconn"
+    "github.com/stretchr/testify/assert"
+
+    "L0/internal/models"
+)
+
+func TestClassifyError(t *testing.T) {
+    duplicate := fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"})
+    connErr := errors.New("connection refused")
+
+    assert.Nil(t, classifyError("op", "uid", nil))
+    assert.Equal(t, models.OrderNotFoundError{OrderUID: "uid"}, classifyError("op", "uid", pgx.ErrNoRows))
+    assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "uid"}, classifyError("op", "uid", duplicate))
+
+    err := classifyError("op", "uid", connErr)
+    var dbErr models.DatabaseError
+    assert.True(t, errors.As(err, &dbErr))
+    assert.Equal(t, "op", dbErr.Operation)
+    assert.ErrorIs(t, err, connErr)
+
+    // Повторная классификация не меняет ошибку
+    assert.Equal(t, err, classifyError("other", "uid", err))
+}
+
+func TestIsPermanent(t *testing.T) {
+    assert.True(t, isPermanent(models.OrderNotFoundError{OrderUID: "uid"}))
+    assert.True(t, isPermanent(fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"})))
+    assert.True(t, isPermanent(&pgconn.PgError{Code: "22001"})) // value too long
+    assert.False(t, isPermanent(&pgconn.PgError{Code: "08006"})) // connection failure
+    assert.False(t, isPermanent(errors.New("connection refused")))
+}
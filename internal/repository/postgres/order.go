@@ -5,18 +5,51 @@ import (
     "fmt"
     "log/slog"
     "strings"
+    "time"
 
     "github.com/cenkalti/backoff/v4"
     "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgxpool"
 
     "L0/internal/config"
+    "L0/internal/metrics"
     "L0/internal/models"
+    "L0/internal/pii"
 )
 
+const (
+    orderSQL = `INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+    deliverySQL = `INSERT INTO deliveries (order_uid, name, phone, zip, city, address, region, email, key_id, dek)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+    paymentSQL = `INSERT INTO payments (order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+    itemSQL = `INSERT INTO items (chrt_id, order_uid, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+)
+
+// Колонки items в порядке itemArgs, используются в CopyFrom
+var itemColumns = []string{"chrt_id", "order_uid", "track_number", "price", "rid", "name", "sale", "size", "total_price", "nm_id", "brand", "status"}
+
+func orderArgs(order models.Order) []any {
+    return []any{order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard}
+}
+
+func paymentArgs(order models.Order) []any {
+    return []any{order.OrderUID, order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider, order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost, order.Payment.GoodsTotal, order.Payment.CustomFee}
+}
+
+func itemArgs(orderUID string, item models.Item) []any {
+    return []any{item.ChrtID, orderUID, item.TrackNumber, item.Price, item.Rid, item.Name, item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status}
+}
+
 type Repository struct {
-    db     *pgxpool.Pool
-    config *config.Config
+    db      *pgxpool.Pool
+    config  *config.Config
+    keyring *pii.Keyring // nil - персональные данные хранятся открыто
 }
 
 func New(db *pgxpool.Pool, cfg *config.Config) *Repository {
@@ -36,32 +69,38 @@ func (r *Repository) Create(ctx context.Context, order models.Order) error {
         }
         defer func() {
             if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
-                slog.Error("failed to rollback transaction", "error", err)
+                slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
             }
         }()
 
-        orderSQL := `INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
-        if _, err := tx.Exec(ctx, orderSQL, order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID, order.DeliveryService, order.Shardkey, order.SmID, order.DateCreated, order.OofShard); err != nil {
+        if _, err := tx.Exec(ctx, orderSQL, orderArgs(order)...); err != nil {
             return fmt.Errorf("%s: %w", op, err)
         }
 
-        deliverySQL := `INSERT INTO deliveries (order_uid, name, phone, zip, city, address, region, email)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
-        if _, err := tx.Exec(ctx, deliverySQL, order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip, order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email); err != nil {
+        delivery, err := r.deliveryArgs(order)
+        if err != nil {
+            return fmt.Errorf("%s: %w", op, err)
+        }
+        if _, err := tx.Exec(ctx, deliverySQL, delivery...); err != nil {
             return fmt.Errorf("%s: %w", op, err)
         }
 
-        paymentSQL := `INSERT INTO payments (order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
-        if _, err := tx.Exec(ctx, paymentSQL, order.OrderUID, order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider, order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost, order.Payment.GoodsTotal, order.Payment.CustomFee); err != nil {
+        if _, err := tx.Exec(ctx, paymentSQL, paymentArgs(order)...); err != nil {
             return fmt.Errorf("%s: %w", op, err)
         }
 
-        itemSQL := `INSERT INTO items (chrt_id, order_uid, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
         for _, item := range order.Items {
-            if _, err := tx.Exec(ctx, itemSQL, item.ChrtID, order.OrderUID, item.TrackNumber, item.Price, item.Rid, item.Name, item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status); err != nil {
+            if _, err := tx.Exec(ctx, itemSQL, itemArgs(order.OrderUID, item)...); err != nil {
+                return fmt.Errorf("%s: %w", op, err)
+            }
+        }
+
+        if r.config.Outbox.Enabled {
+            event, err := outboxArgs(order)
+            if err != nil {
+                return fmt.Errorf("%s: %w", op, err)
+            }
+            if _, err := tx.Exec(ctx, outboxSQL, event...); err != nil {
                 return fmt.Errorf("%s: %w", op, err)
             }
         }
@@ -81,16 +120,20 @@ func (r *Repository) Create(ctx context.Context, order models.Order) error {
     retryable := func() error {
         err := operation()
         if err != nil {
-            // Не retry constraint violations (дубликаты)
-            if strings.Contains(err.Error(), "duplicate key") {
+            // Не retry дубликаты и нарушения ограничений
+            if isPermanent(err) {
                 return backoff.Permanent(err)
             }
-            slog.Warn("Database operation failed, retrying...", "error", err)
+            metrics.DBRetries.WithLabelValues("create").Inc()
+            slog.WarnContext(ctx, "Database operation failed, retrying...", "error", err)
         }
         return err
     }
 
-    return backoff.Retry(retryable, backoff.WithContext(bo, ctx))
+    start := time.Now()
+    err := backoff.Retry(retryable, backoff.WithContext(bo, ctx))
+    metrics.DBOperationDuration.WithLabelValues("create", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    return classifyError(op, order.OrderUID, err)
 }
 
 func (r *Repository) GetByUID(ctx context.Context, uid string) (models.Order, error) {
@@ -103,7 +146,7 @@ func (r *Repository) GetByUID(ctx context.Context, uid string) (models.Order, er
         }
         defer func() {
             if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
-                slog.Error("failed to rollback transaction", "error", err)
+                slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
             }
         }()
 
@@ -115,10 +158,7 @@ func (r *Repository) GetByUID(ctx context.Context, uid string) (models.Order, er
             &order.InternalSignature, &order.CustomerID, &order.DeliveryService,
             &order.Shardkey, &order.SmID, &order.DateCreated, &order.OofShard)
         if err != nil {
-            if err == pgx.ErrNoRows {
-                return models.Order{}, fmt.Errorf("order not found")
-            }
-            return models.Order{}, fmt.Errorf("%s: %w", op, err)
+            return models.Order{}, classifyError(op, uid, err)
         }
 
         // Получаем связанные данные (delivery, payment, items)
@@ -143,19 +183,22 @@ func (r *Repository) GetByUID(ctx context.Context, uid string) (models.Order, er
     retryable := func() error {
         order, err := operation()
         if err != nil {
-            if strings.Contains(err.Error(), "order not found") {
+            if isPermanent(err) {
                 return backoff.Permanent(err)
             }
-            slog.Warn("Database read operation failed, retrying...", "error", err)
+            metrics.DBRetries.WithLabelValues("get_by_uid").Inc()
+            slog.WarnContext(ctx, "Database read operation failed, retrying...", "error", err)
             return err
         }
         result = order
         return nil
     }
 
+    start := time.Now()
     err := backoff.Retry(retryable, backoff.WithContext(bo, ctx))
+    metrics.DBOperationDuration.WithLabelValues("get_by_uid", metrics.Status(err)).Observe(time.Since(start).Seconds())
     if err != nil {
-        return models.Order{}, err
+        return models.Order{}, classifyError(op, uid, err)
     }
     return result, nil
 }
@@ -170,50 +213,77 @@ func (r *Repository) GetLatest(ctx context.Context, limit int) ([]models.Order,
         }
         defer func() {
             if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
-                slog.Error("failed to rollback transaction", "error", err)
+                slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
             }
         }()
 
-        orderRows, err := tx.Query(ctx, `
+        orders, err := r.queryOrders(ctx, tx, `
             SELECT order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
             FROM orders
-            ORDER BY date_created DESC
+            ORDER BY date_created DESC, order_uid DESC
             LIMIT $1
         `, limit)
         if err != nil {
-            return nil, fmt.Errorf("%s: query orders: %w", op, err)
+            return nil, fmt.Errorf("%s: %w", op, err)
         }
-        defer orderRows.Close()
-
-        var orders []models.Order
-        orderMap := make(map[string]*models.Order)
 
-        for orderRows.Next() {
-            var o models.Order
-            if err := orderRows.Scan(&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature, &o.CustomerID, &o.DeliveryService, &o.Shardkey, &o.SmID, &o.DateCreated, &o.OofShard); err != nil {
-                return nil, fmt.Errorf("%s: scan order: %w", op, err)
-            }
-            orders = append(orders, o)
-            orderMap[o.OrderUID] = &orders[len(orders)-1]
-        }
-        if err := orderRows.Err(); err != nil {
-            return nil, fmt.Errorf("%s: iterate orders: %w", op, err)
+        if err := tx.Commit(ctx); err != nil {
+            return nil, fmt.Errorf("%s: commit: %w", op, err)
         }
 
-        if len(orders) == 0 {
-            if err := tx.Commit(ctx); err != nil {
-                return nil, fmt.Errorf("%s: commit: %w", op, err)
-            }
-            return []models.Order{}, nil
+        return orders, nil
+    }
+
+    // Retry для read операций
+    bo := backoff.NewExponentialBackOff()
+    bo.MaxElapsedTime = r.config.Retry.MaxElapsedTimeRead
+    bo.InitialInterval = r.config.Retry.InitialInterval
+    bo.MaxInterval = r.config.Retry.MaxIntervalRead
+
+    var result []models.Order
+    retryable := func() error {
+        orders, err := operation()
+        if err != nil {
+            metrics.DBRetries.WithLabelValues("get_latest").Inc()
+            slog.WarnContext(ctx, "Database read operation failed, retrying...", "error", err)
+            return err
         }
+        result = orders
+        return nil
+    }
+
+    start := time.Now()
+    err := backoff.Retry(retryable, backoff.WithContext(bo, ctx))
+    metrics.DBOperationDuration.WithLabelValues("get_latest", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return nil, classifyError(op, "", err)
+    }
+    return result, nil
+}
+
+// List возвращает страницу заказов, отсортированных по (date_created, order_uid) по убыванию
+func (r *Repository) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    const op = "repository.postgres.List"
+
+    if filter.Limit < 1 {
+        return models.OrderPage{}, fmt.Errorf("%s: %w", op, models.ErrInvalidLimit)
+    }
+
+    query, args := buildListQuery(filter)
 
-        orderUIDs := make([]string, 0, len(orders))
-        for _, o := range orders {
-            orderUIDs = append(orderUIDs, o.OrderUID)
+    operation := func() ([]models.Order, error) {
+        tx, err := r.db.Begin(ctx)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", op, err)
         }
+        defer func() {
+            if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+                slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
+            }
+        }()
 
-        // Получаем связанные данные для всех заказов
-        if err := r.queryDeliveryPaymentItems(ctx, tx, orderMap, orderUIDs); err != nil {
+        orders, err := r.queryOrders(ctx, tx, query, args...)
+        if err != nil {
             return nil, fmt.Errorf("%s: %w", op, err)
         }
 
@@ -234,18 +304,113 @@ func (r *Repository) GetLatest(ctx context.Context, limit int) ([]models.Order,
     retryable := func() error {
         orders, err := operation()
         if err != nil {
-            slog.Warn("Database read operation failed, retrying...", "error", err)
+            metrics.DBRetries.WithLabelValues("list").Inc()
+            slog.WarnContext(ctx, "Database read operation failed, retrying...", "error", err)
             return err
         }
         result = orders
         return nil
     }
 
+    start := time.Now()
     err := backoff.Retry(retryable, backoff.WithContext(bo, ctx))
+    metrics.DBOperationDuration.WithLabelValues("list", metrics.Status(err)).Observe(time.Since(start).Seconds())
     if err != nil {
+        return models.OrderPage{}, classifyError(op, "", err)
+    }
+
+    // Запрашивали на одну запись больше, чтобы понять, есть ли следующая страница
+    page := models.OrderPage{Orders: result}
+    if len(result) > filter.Limit {
+        page.Orders = result[:filter.Limit]
+        page.NextCursor = models.CursorAfter(page.Orders[len(page.Orders)-1])
+    }
+    return page, nil
+}
+
+func buildListQuery(filter models.OrderFilter) (string, []any) {
+    var conditions []string
+    var args []any
+
+    arg := func(v any) string {
+        args = append(args, v)
+        return fmt.Sprintf("$%d", len(args))
+    }
+
+    if filter.CustomerID != "" {
+        conditions = append(conditions, "o.customer_id = "+arg(filter.CustomerID))
+    }
+    if filter.TrackNumber != "" {
+        conditions = append(conditions, "o.track_number = "+arg(filter.TrackNumber))
+    }
+    if filter.DeliveryService != "" {
+        conditions = append(conditions, "o.delivery_service = "+arg(filter.DeliveryService))
+    }
+    if filter.Locale != "" {
+        conditions = append(conditions, "o.locale = "+arg(filter.Locale))
+    }
+    if filter.Currency != "" {
+        conditions = append(conditions, "EXISTS (SELECT 1 FROM payments p WHERE p.order_uid = o.order_uid AND p.currency = "+arg(filter.Currency)+")")
+    }
+    if !filter.CreatedFrom.IsZero() {
+        conditions = append(conditions, "o.date_created >= "+arg(filter.CreatedFrom))
+    }
+    if !filter.CreatedTo.IsZero() {
+        conditions = append(conditions, "o.date_created < "+arg(filter.CreatedTo))
+    }
+    if filter.Cursor != nil {
+        conditions = append(conditions, fmt.Sprintf("(o.date_created, o.order_uid) < (%s, %s)", arg(filter.Cursor.DateCreated), arg(filter.Cursor.OrderUID)))
+    }
+
+    query := `
+            SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard
+            FROM orders o`
+    if len(conditions) > 0 {
+        query += "\n            WHERE " + strings.Join(conditions, " AND ")
+    }
+    query += "\n            ORDER BY o.date_created DESC, o.order_uid DESC\n            LIMIT " + arg(filter.Limit+1)
+
+    return query, args
+}
+
+// queryOrders выполняет запрос к orders и подгружает delivery, payment и items найденных заказов
+func (r *Repository) queryOrders(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]models.Order, error) {
+    rows, err := tx.Query(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("query orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders := []models.Order{}
+    for rows.Next() {
+        var o models.Order
+        if err := rows.Scan(&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature, &o.CustomerID, &o.DeliveryService, &o.Shardkey, &o.SmID, &o.DateCreated, &o.OofShard); err != nil {
+            return nil, fmt.Errorf("scan order: %w", err)
+        }
+        orders = append(orders, o)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("iterate orders: %w", err)
+    }
+
+    if len(orders) == 0 {
+        return orders, nil
+    }
+
+    // Указатели берём после заполнения слайса: append мог переаллоцировать массив
+    orderMap := make(map[string]*models.Order, len(orders))
+    orderUIDs := make([]string, 0, len(orders))
+    for i := range orders {
+        orderMap[orders[i].OrderUID] = &orders[i]
+        orderUIDs = append(orderUIDs, orders[i].OrderUID)
+    }
+
+    // Получаем связанные данные для всех заказов
+    if err := r.queryDeliveryPaymentItems(ctx, tx, orderMap, orderUIDs); err != nil {
         return nil, err
     }
-    return result, nil
+
+    return orders, nil
 }
 
 func (r *Repository) queryDeliveryPaymentItems(ctx context.Context, tx pgx.Tx, orderMap map[string]*models.Order, orderUIDs []string) error {
@@ -253,7 +418,7 @@ func (r *Repository) queryDeliveryPaymentItems(ctx context.Context, tx pgx.Tx, o
 
     // Delivery
     deliveryRows, err := tx.Query(ctx, `
-        SELECT order_uid, name, phone, zip, city, address, region, email
+        SELECT order_uid, name, phone, zip, city, address, region, email, key_id, dek
         FROM deliveries WHERE order_uid = ANY($1)
     `, orderUIDs)
     if err != nil {
@@ -264,9 +429,14 @@ func (r *Repository) queryDeliveryPaymentItems(ctx context.Context, tx pgx.Tx, o
     for deliveryRows.Next() {
         var d models.Delivery
         var orderUID string
-        if err := deliveryRows.Scan(&orderUID, &d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email); err != nil {
+        var keyID *string
+        var dek []byte
+        if err := deliveryRows.Scan(&orderUID, &d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email, &keyID, &dek); err != nil {
             return fmt.Errorf("%s: scan delivery: %w", op, err)
         }
+        if err := r.decryptDelivery(orderUID, &d, keyID, dek); err != nil {
+            return fmt.Errorf("%s: %w", op, err)
+        }
         if order, ok := orderMap[orderUID]; ok {
             order.Delivery = d
         }
@@ -297,6 +467,7 @@ func (r *Repository) queryDeliveryPaymentItems(ctx context.Context, tx pgx.Tx, o
     itemRows, err := tx.Query(ctx, `
         SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
         FROM items WHERE order_uid = ANY($1)
+        ORDER BY id
     `, orderUIDs)
     if err != nil {
         return fmt.Errorf("%s: query items: %w", op, err)
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+)
+
+const outboxSQL = `INSERT INTO outbox (event_type, event_key, payload) VALUES ($1, $2, $3)`
+
+// outboxArgs - аргументы outboxSQL для события order.stored. Пишется в той же
+// транзакции, что и заказ: откаченный заказ не оставляет события
+func outboxArgs(order models.Order) ([]any, error) {
+    payload, err := json.Marshal(models.NewOrderStoredEvent(order, time.Now().UTC()))
+    if err != nil {
+        return nil, fmt.Errorf("marshal %s event: %w", models.EventOrderStored, err)
+    }
+    return []any{models.EventOrderStored, order.OrderUID, payload}, nil
+}
+
+// PublishOutbox забирает до limit неотправленных событий в порядке записи и
+// передаёт их publish. Если publish вернул nil, события отмечаются
+// отправленными в той же транзакции. Строки блокируются через SKIP LOCKED,
+// поэтому несколько экземпляров сервиса не публикуют одно событие одновременно.
+// Пока работает publish, транзакция держит соединение и блокировки, поэтому
+// publish должен ограничивать своё время. Возвращает число отправленных событий
+func (r *Repository) PublishOutbox(ctx context.Context, limit int, publish func(context.Context, []models.OutboxMessage) error) (int, error) {
+    const op = "repository.postgres.PublishOutbox"
+
+    start := time.Now()
+    n, err := r.publishOutbox(ctx, limit, publish)
+    metrics.DBOperationDuration.WithLabelValues("publish_outbox", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return 0, models.DatabaseError{Operation: op, Err: err}
+    }
+    return n, nil
+}
+
+func (r *Repository) publishOutbox(ctx context.Context, limit int, publish func(context.Context, []models.OutboxMessage) error) (int, error) {
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("begin transaction: %w", err)
+    }
+    defer func() {
+        if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+            slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
+        }
+    }()
+
+    rows, err := tx.Query(ctx, `
+        SELECT id, event_type, event_key, payload, created_at
+        FROM outbox
+        WHERE sent_at IS NULL
+        ORDER BY id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED`, limit)
+    if err != nil {
+        return 0, fmt.Errorf("select outbox: %w", err)
+    }
+
+    var messages []models.OutboxMessage
+    ids := make([]int64, 0, limit)
+    for rows.Next() {
+        var m models.OutboxMessage
+        if err := rows.Scan(&m.ID, &m.EventType, &m.Key, &m.Payload, &m.CreatedAt); err != nil {
+            rows.Close()
+            return 0, fmt.Errorf("scan outbox: %w", err)
+        }
+        messages = append(messages, m)
+        ids = append(ids, m.ID)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("select outbox: %w", err)
+    }
+
+    if len(messages) == 0 {
+        return 0, nil
+    }
+
+    if err := publish(ctx, messages); err != nil {
+        return 0, fmt.Errorf("publish outbox: %w", err)
+    }
+
+    if _, err := tx.Exec(ctx, `UPDATE outbox SET sent_at = NOW() WHERE id = ANY($1)`, ids); err != nil {
+        return 0, fmt.Errorf("mark outbox sent: %w", err)
+    }
+
+    if err := tx.Commit(ctx); err != nil {
+        return 0, fmt.Errorf("commit: %w", err)
+    }
+    return len(messages), nil
+}
+
+// DeleteSentOutbox удаляет события, опубликованные раньше before.
+// Неотправленные события не удаляются
+func (r *Repository) DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error) {
+    const op = "repository.postgres.DeleteSentOutbox"
+
+    start := time.Now()
+    tag, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE sent_at IS NOT NULL AND sent_at < $1`, before)
+    metrics.DBOperationDuration.WithLabelValues("delete_sent_outbox", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return 0, models.DatabaseError{Operation: op, Err: err}
+    }
+    return tag.RowsAffected(), nil
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "time"
+
+    "github.com/jackc/pgx/v5"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+    "L0/internal/pii"
+)
+
+// Зашифрованные колонки deliveries. Имя колонки входит в associated data шифротекста
+var encryptedDeliveryFields = []struct {
+    column string
+    field  func(d *models.Delivery) *string
+}{
+    {"name", func(d *models.Delivery) *string { return &d.Name }},
+    {"phone", func(d *models.Delivery) *string { return &d.Phone }},
+    {"address", func(d *models.Delivery) *string { return &d.Address }},
+    {"email", func(d *models.Delivery) *string { return &d.Email }},
+}
+
+// WithKeyring включает шифрование имени, телефона, адреса и email покупателя.
+// Строки, записанные без шифрования, продолжают читаться
+func (r *Repository) WithKeyring(keyring *pii.Keyring) *Repository {
+    r.keyring = keyring
+    return r
+}
+
+// deliveryArgs - аргументы deliverySQL. С ключами персональные данные
+// шифруются ключом данных заказа, который сохраняется в key_id и dek
+func (r *Repository) deliveryArgs(order models.Order) ([]any, error) {
+    d := order.Delivery
+    var keyID *string
+    var dek []byte
+
+    if r.keyring != nil {
+        env, err := r.keyring.NewEnvelope(order.OrderUID)
+        if err != nil {
+            return nil, err
+        }
+        if err := encryptDelivery(env, &d); err != nil {
+            return nil, err
+        }
+        keyID, dek = &env.KeyID, env.WrappedDEK
+    }
+
+    return []any{order.OrderUID, d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email, keyID, dek}, nil
+}
+
+func encryptDelivery(env pii.Envelope, d *models.Delivery) error {
+    for _, f := range encryptedDeliveryFields {
+        value := f.field(d)
+        encrypted, err := env.Encrypt(f.column, *value)
+        if err != nil {
+            return fmt.Errorf("encrypt delivery %s: %w", f.column, err)
+        }
+        *value = encrypted
+    }
+    return nil
+}
+
+// decryptDelivery расшифровывает доставку, прочитанную из БД. keyID == nil - строка не зашифрована
+func (r *Repository) decryptDelivery(orderUID string, d *models.Delivery, keyID *string, dek []byte) error {
+    if keyID == nil {
+        return nil
+    }
+    if r.keyring == nil {
+        return fmt.Errorf("delivery of order %s is encrypted, but PII_ENCRYPTION_KEY_FILE is not set", orderUID)
+    }
+
+    env, err := r.keyring.OpenEnvelope(*keyID, dek, orderUID)
+    if err != nil {
+        return fmt.Errorf("delivery of order %s: %w", orderUID, err)
+    }
+    for _, f := range encryptedDeliveryFields {
+        value := f.field(d)
+        if *value, err = env.Decrypt(f.column, *value); err != nil {
+            return fmt.Errorf("delivery of order %s: %w", orderUID, err)
+        }
+    }
+    return nil
+}
+
+// RewrapPII доводит все строки deliveries до основного ключа: ключи данных,
+// зашифрованные старыми мастер-ключами, перешифровываются, а строки,
+// записанные до включения шифрования, шифруются. Работает пачками по
+// batchSize строк в отдельных транзакциях, поэтому его можно прервать и
+// запустить снова. Возвращает число обновлённых строк
+func (r *Repository) RewrapPII(ctx context.Context, batchSize int) (int, error) {
+    const op = "repository.postgres.RewrapPII"
+
+    if r.keyring == nil {
+        return 0, fmt.Errorf("%s: PII_ENCRYPTION_KEY_FILE is not set", op)
+    }
+
+    start := time.Now()
+    total := 0
+    for {
+        n, err := r.rewrapBatch(ctx, batchSize)
+        total += n
+        if err != nil {
+            metrics.DBOperationDuration.WithLabelValues("rewrap_pii", metrics.Status(err)).Observe(time.Since(start).Seconds())
+            return total, models.DatabaseError{Operation: op, Err: err}
+        }
+        if n > 0 {
+            slog.InfoContext(ctx, "Re-encrypted deliveries", "rows", n, "total", total, "key_id", r.keyring.Primary())
+        }
+        if n < batchSize {
+            break
+        }
+    }
+
+    metrics.DBOperationDuration.WithLabelValues("rewrap_pii", "ok").Observe(time.Since(start).Seconds())
+    return total, nil
+}
+
+func (r *Repository) rewrapBatch(ctx context.Context, batchSize int) (int, error) {
+    tx, err := r.db.Begin(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("begin transaction: %w", err)
+    }
+    defer func() {
+        if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+            slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
+        }
+    }()
+
+    // SKIP LOCKED: строки, которые прямо сейчас пишет сервис, достанутся следующей пачке
+    rows, err := tx.Query(ctx, `
+        SELECT order_uid, name, phone, address, email, key_id, dek
+        FROM deliveries
+        WHERE key_id IS DISTINCT FROM $1
+        ORDER BY order_uid
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    `, r.keyring.Primary(), batchSize)
+    if err != nil {
+        return 0, fmt.Errorf("query deliveries: %w", err)
+    }
+
+    type row struct {
+        orderUID string
+        delivery models.Delivery
+        keyID    *string
+        dek      []byte
+    }
+    var batch []row
+    for rows.Next() {
+        var rw row
+        d := &rw.delivery
+        if err := rows.Scan(&rw.orderUID, &d.Name, &d.Phone, &d.Address, &d.Email, &rw.keyID, &rw.dek); err != nil {
+            rows.Close()
+            return 0, fmt.Errorf("scan delivery: %w", err)
+        }
+        batch = append(batch, rw)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, fmt.Errorf("iterate deliveries: %w", err)
+    }
+
+    update := &pgx.Batch{}
+    for _, rw := range batch {
+        if rw.keyID != nil {
+            // Поля уже зашифрованы ключом данных, меняется только обёртка этого ключа
+            env, err := r.keyring.Rewrap(*rw.keyID, rw.dek, rw.orderUID)
+            if err != nil {
+                return 0, fmt.Errorf("delivery of order %s: %w", rw.orderUID, err)
+            }
+            update.Queue(`UPDATE deliveries SET key_id = $2, dek = $3 WHERE order_uid = $1`, rw.orderUID, env.KeyID, env.WrappedDEK)
+            continue
+        }
+
+        env, err := r.keyring.NewEnvelope(rw.orderUID)
+        if err != nil {
+            return 0, err
+        }
+        d := rw.delivery
+        if err := encryptDelivery(env, &d); err != nil {
+            return 0, err
+        }
+        update.Queue(`UPDATE deliveries SET name = $2, phone = $3, address = $4, email = $5, key_id = $6, dek = $7 WHERE order_uid = $1`,
+            rw.orderUID, d.Name, d.Phone, d.Address, d.Email, env.KeyID, env.WrappedDEK)
+    }
+
+    if len(batch) > 0 {
+        if err := tx.SendBatch(ctx, update).Close(); err != nil {
+            return 0, fmt.Errorf("update deliveries: %w", err)
+        }
+    }
+    if err := tx.Commit(ctx); err != nil {
+        return 0, fmt.Errorf("commit: %w", err)
+    }
+    return len(batch), nil
+}
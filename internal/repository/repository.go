@@ -2,12 +2,30 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"L0/internal/models"
 )
 
 type OrderRepository interface {
 	Create(ctx context.Context, order models.Order) error
+	// CreateBatch возвращает ошибку для каждого заказа пачки (nil - сохранён)
+	CreateBatch(ctx context.Context, orders []models.Order) ([]error, error)
 	GetByUID(ctx context.Context, uid string) (models.Order, error)
 	GetLatest(ctx context.Context, limit int) ([]models.Order, error)
+	List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error)
+}
+
+// APIKeyRepository ищет действующий API-ключ по хэшу (models.HashAPIKey)
+type APIKeyRepository interface {
+	GetAPIKey(ctx context.Context, keyHash string) (models.APIKey, error)
+}
+
+// OutboxRepository - transactional outbox: события, записанные вместе с заказом
+type OutboxRepository interface {
+	// PublishOutbox передаёт publish до limit неотправленных событий и отмечает
+	// их отправленными, если publish вернул nil. Возвращает число отправленных
+	PublishOutbox(ctx context.Context, limit int, publish func(context.Context, []models.OutboxMessage) error) (int, error)
+	// DeleteSentOutbox удаляет события, отправленные раньше before
+	DeleteSentOutbox(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,310 @@
+// Package repotest содержит общий набор тестов, которому должна
+// удовлетворять любая реализация repository.OrderRepository.
+package repotest
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "L0/internal/models"
+    "L0/internal/repository"
+)
+
+// Factory возвращает пустое хранилище для очередного подтеста
+type Factory func(t *testing.T) repository.OrderRepository
+
+// base без долей секунды, чтобы время без потерь переживало round-trip через БД
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NewOrder собирает валидный заказ с одним товаром
+func NewOrder(uid, customer string, created time.Time) models.Order {
+    track := "TRACK-" + uid
+    return models.Order{
+        OrderUID:        uid,
+        TrackNumber:     track,
+        Entry:           "WBIL",
+        Locale:          "en",
+        CustomerID:      customer,
+        DeliveryService: "meest",
+        Shardkey:        "9",
+        SmID:            99,
+        DateCreated:     created,
+        OofShard:        "1",
+        Delivery: models.Delivery{
+            Name:    "Test Testov",
+            Phone:   "+9720000000",
+            Zip:     "2639809",
+            City:    "Kiryat Mozkin",
+            Address: "Ploshad Mira 15",
+            Region:  "Kraiot",
+            Email:   "test@gmail.com",
+        },
+        Payment: models.Payment{
+            Transaction:  uid,
+            Currency:     "USD",
+            Provider:     "wbpay",
+            Amount:       1817,
+            PaymentDt:    1637907727,
+            Bank:         "alpha",
+            DeliveryCost: 1500,
+            GoodsTotal:   317,
+        },
+        Items: []models.Item{
+            {ChrtID: 9934930, TrackNumber: track, Price: 453, Rid: "ab4219087a764ae0btest", Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202},
+        },
+    }
+}
+
+// Run запускает набор тестов против реализации, создаваемой newRepo
+func Run(t *testing.T, newRepo Factory) {
+    t.Run("CreateAndGet", func(t *testing.T) { testCreateAndGet(t, newRepo(t)) })
+    t.Run("NotFound", func(t *testing.T) { testNotFound(t, newRepo(t)) })
+    t.Run("Duplicate", func(t *testing.T) { testDuplicate(t, newRepo(t)) })
+    t.Run("CreateBatch", func(t *testing.T) { testCreateBatch(t, newRepo(t)) })
+    t.Run("GetLatest", func(t *testing.T) { testGetLatest(t, newRepo(t)) })
+    t.Run("List", func(t *testing.T) { testList(t, newRepo(t)) })
+    t.Run("ConcurrentCreate", func(t *testing.T) { testConcurrentCreate(t, newRepo(t)) })
+}
+
+// assertOrder сравнивает заказы без учёта часового пояса даты создания
+func assertOrder(t *testing.T, want, got models.Order) {
+    t.Helper()
+    assert.True(t, want.DateCreated.Equal(got.DateCreated), "date_created: want %v, got %v", want.DateCreated, got.DateCreated)
+    got.DateCreated = want.DateCreated
+    assert.Equal(t, want, got)
+}
+
+func uids(orders []models.Order) []string {
+    result := make([]string, 0, len(orders))
+    for _, o := range orders {
+        result = append(result, o.OrderUID)
+    }
+    return result
+}
+
+func testCreateAndGet(t *testing.T, repo repository.OrderRepository) {
+    ctx := context.Background()
+    order := NewOrder("create-1", "customer-1", base)
+    order.Items = append(order.Items, models.Item{ChrtID: 2, TrackNumber: order.TrackNumber, Price: 100, Name: "Second", TotalPrice: 100})
+
+    require.NoError(t, repo.Create(ctx, order))
+
+    // Изменение переданного заказа после сохранения тоже ни на что не влияет
+    want := order
+    want.Items = append([]models.Item(nil), order.Items...)
+    order.Items[0].Name = "changed"
+
+    got, err := repo.GetByUID(ctx, order.OrderUID)
+    require.NoError(t, err)
+    assertOrder(t, want, got)
+
+    // Изменение полученного заказа не затрагивает сохранённый
+    got.Items[0].Name = "changed"
+    got.Delivery.City = "changed"
+    again, err := repo.GetByUID(ctx, order.OrderUID)
+    require.NoError(t, err)
+    assertOrder(t, want, again)
+}
+
+func testNotFound(t *testing.T, repo repository.OrderRepository) {
+    _, err := repo.GetByUID(context.Background(), "missing-uid")
+    assert.Equal(t, models.OrderNotFoundError{OrderUID: "missing-uid"}, err)
+}
+
+func testDuplicate(t *testing.T, repo repository.OrderRepository) {
+    ctx := context.Background()
+    order := NewOrder("duplicate-uid", "customer-1", base)
+    require.NoError(t, repo.Create(ctx, order))
+
+    changed := order
+    changed.CustomerID = "customer-2"
+    err := repo.Create(ctx, changed)
+    assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "duplicate-uid"}, err)
+
+    // Первая версия заказа не перезаписана
+    got, err := repo.GetByUID(ctx, order.OrderUID)
+    require.NoError(t, err)
+    assert.Equal(t, "customer-1", got.CustomerID)
+}
+
+func testCreateBatch(t *testing.T, repo repository.OrderRepository) {
+    ctx := context.Background()
+    require.NoError(t, repo.Create(ctx, NewOrder("batch-existing", "customer-1", base)))
+
+    batch := []models.Order{
+        NewOrder("batch-1", "customer-1", base.Add(time.Minute)),
+        NewOrder("batch-existing", "customer-1", base),
+        NewOrder("batch-2", "customer-1", base.Add(2*time.Minute)),
+        NewOrder("batch-1", "customer-1", base.Add(time.Minute)),
+    }
+    results, err := repo.CreateBatch(ctx, batch)
+    require.NoError(t, err)
+    require.Len(t, results, len(batch))
+    assert.NoError(t, results[0])
+    assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "batch-existing"}, results[1])
+    assert.NoError(t, results[2])
+    assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "batch-1"}, results[3])
+
+    for _, i := range []int{0, 2} {
+        got, err := repo.GetByUID(ctx, batch[i].OrderUID)
+        require.NoError(t, err)
+        assertOrder(t, batch[i], got)
+    }
+}
+
+func testGetLatest(t *testing.T, repo repository.OrderRepository) {
+    ctx := context.Background()
+
+    // Порядок вставки не совпадает с порядком date_created
+    for _, o := range []models.Order{
+        NewOrder("latest-2", "customer-1", base.Add(2*time.Hour)),
+        NewOrder("latest-1", "customer-1", base.Add(time.Hour)),
+        NewOrder("latest-4", "customer-1", base.Add(4*time.Hour)),
+        NewOrder("latest-3", "customer-1", base.Add(3*time.Hour)),
+    } {
+        require.NoError(t, repo.Create(ctx, o))
+    }
+
+    latest, err := repo.GetLatest(ctx, 3)
+    require.NoError(t, err)
+    assert.Equal(t, []string{"latest-4", "latest-3", "latest-2"}, uids(latest))
+    for _, o := range latest {
+        assert.Equal(t, o.OrderUID, o.Payment.Transaction)
+        assert.Len(t, o.Items, 1)
+    }
+
+    all, err := repo.GetLatest(ctx, 10)
+    require.NoError(t, err)
+    assert.Len(t, all, 4)
+}
+
+func testList(t *testing.T, repo repository.OrderRepository) {
+    ctx := context.Background()
+
+    newOrder := func(uid, customer, currency string, created time.Time) models.Order {
+        o := NewOrder(uid, customer, created)
+        o.Payment.Currency = currency
+        return o
+    }
+
+    for _, o := range []models.Order{
+        newOrder("list-1", "alice", "USD", base),
+        newOrder("list-2", "bob", "EUR", base.Add(time.Hour)),
+        newOrder("list-3", "alice", "USD", base.Add(2*time.Hour)),
+        newOrder("list-4", "alice", "EUR", base.Add(3*time.Hour)),
+        // Та же дата, что у list-4: порядок определяется order_uid
+        newOrder("list-5", "bob", "USD", base.Add(3*time.Hour)),
+    } {
+        require.NoError(t, repo.Create(ctx, o))
+    }
+
+    t.Run("pagination", func(t *testing.T) {
+        var got []string
+        filter := models.OrderFilter{Limit: 2}
+        for pages := 0; ; pages++ {
+            require.Less(t, pages, 5, "pagination does not terminate")
+
+            page, err := repo.List(ctx, filter)
+            require.NoError(t, err)
+            got = append(got, uids(page.Orders)...)
+            if page.NextCursor == nil {
+                break
+            }
+            filter.Cursor = page.NextCursor
+        }
+        assert.Equal(t, []string{"list-5", "list-4", "list-3", "list-2", "list-1"}, got)
+    })
+
+    t.Run("filters", func(t *testing.T) {
+        page, err := repo.List(ctx, models.OrderFilter{
+            CustomerID:  "alice",
+            Currency:    "USD",
+            CreatedFrom: base.Add(time.Minute),
+            Limit:       10,
+        })
+        require.NoError(t, err)
+        assert.Equal(t, []string{"list-3"}, uids(page.Orders))
+        assert.Nil(t, page.NextCursor)
+    })
+
+    t.Run("created range is half-open", func(t *testing.T) {
+        page, err := repo.List(ctx, models.OrderFilter{
+            CreatedFrom: base.Add(time.Hour),
+            CreatedTo:   base.Add(3 * time.Hour),
+            Limit:       10,
+        })
+        require.NoError(t, err)
+        assert.Equal(t, []string{"list-3", "list-2"}, uids(page.Orders))
+    })
+
+    t.Run("no matches", func(t *testing.T) {
+        page, err := repo.List(ctx, models.OrderFilter{TrackNumber: "missing", Limit: 10})
+        require.NoError(t, err)
+        assert.Empty(t, page.Orders)
+        assert.Nil(t, page.NextCursor)
+    })
+
+    t.Run("limit is required", func(t *testing.T) {
+        _, err := repo.List(ctx, models.OrderFilter{})
+        assert.ErrorIs(t, err, models.ErrInvalidLimit)
+    })
+}
+
+func testConcurrentCreate(t *testing.T, repo repository.OrderRepository) {
+    ctx := context.Background()
+
+    const writers, perWriter = 4, 10
+
+    var wg sync.WaitGroup
+    errs := make(chan error, writers*perWriter)
+    for w := 0; w < writers; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < perWriter; i++ {
+                uid := fmt.Sprintf("concurrent-%d-%d", w, i)
+                errs <- repo.Create(ctx, NewOrder(uid, "customer-1", base.Add(time.Duration(i)*time.Second)))
+            }
+        }(w)
+    }
+
+    // Все пишут один и тот же заказ: сохраняется ровно одна копия
+    var dupWG sync.WaitGroup
+    dupResults := make(chan error, writers)
+    for w := 0; w < writers; w++ {
+        dupWG.Add(1)
+        go func() {
+            defer dupWG.Done()
+            dupResults <- repo.Create(ctx, NewOrder("concurrent-dup", "customer-1", base))
+        }()
+    }
+
+    wg.Wait()
+    dupWG.Wait()
+    close(errs)
+    close(dupResults)
+
+    for err := range errs {
+        require.NoError(t, err)
+    }
+
+    stored := 0
+    for err := range dupResults {
+        if err == nil {
+            stored++
+            continue
+        }
+        assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "concurrent-dup"}, err)
+    }
+    assert.Equal(t, 1, stored)
+
+    all, err := repo.GetLatest(ctx, writers*perWriter+1)
+    require.NoError(t, err)
+    assert.Len(t, all, writers*perWriter+1)
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "time"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+)
+
+// GetAPIKey ищет неотозванный ключ по хэшу
+func (r *Repository) GetAPIKey(ctx context.Context, keyHash string) (models.APIKey, error) {
+    const op = "repository.sqlite.GetAPIKey"
+
+    var key models.APIKey
+    start := time.Now()
+    err := r.db.QueryRowContext(ctx, `SELECT name, role FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL`, keyHash).
+        Scan(&key.Name, &key.Role)
+    metrics.DBOperationDuration.WithLabelValues("get_api_key", metrics.Status(err)).Observe(time.Since(start).Seconds())
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.APIKey{}, models.ErrAPIKeyNotFound
+    }
+    if err != nil {
+        return models.APIKey{}, models.DatabaseError{Operation: op, Err: err}
+    }
+    return key, nil
+}
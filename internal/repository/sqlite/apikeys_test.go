@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+    "context"
+    "path/filepath"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+
+    "L0/internal/migrations"
+    "L0/internal/models"
+)
+
+func TestRepository_GetAPIKey(t *testing.T) {
+    ctx := context.Background()
+
+    db, err := Open(filepath.Join(t.TempDir(), "orders.db"))
+    require.NoError(t, err)
+    t.Cleanup(func() { db.Close() })
+
+    migrator, err := migrations.NewSQLite(db)
+    require.NoError(t, err)
+    _, err = migrator.Up(ctx)
+    require.NoError(t, err)
+
+    _, err = db.ExecContext(ctx, `INSERT INTO api_keys (key_hash, name, role) VALUES (?, 'team', 'support'), (?, 'old', 'admin')`,
+        models.HashAPIKey("active"), models.HashAPIKey("revoked"))
+    require.NoError(t, err)
+    _, err = db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = 1 WHERE name = 'old'`)
+    require.NoError(t, err)
+
+    repo := New(db)
+
+    key, err := repo.GetAPIKey(ctx, models.HashAPIKey("active"))
+    require.NoError(t, err)
+    assert.Equal(t, models.APIKey{Name: "team", Role: models.RoleSupport}, key)
+
+    _, err = repo.GetAPIKey(ctx, models.HashAPIKey("revoked"))
+    assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+
+    _, err = repo.GetAPIKey(ctx, models.HashAPIKey("unknown"))
+    assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+}
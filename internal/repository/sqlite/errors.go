@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+    "database/sql"
+    "errors"
+
+    "L0/internal/models"
+)
+
+// classifyError переводит ошибку database/sql в типизированную ошибку из models
+func classifyError(op, orderUID string, err error) error {
+    if err == nil {
+        return nil
+    }
+
+    if errors.Is(err, sql.ErrNoRows) {
+        return models.OrderNotFoundError{OrderUID: orderUID}
+    }
+
+    // Уже классифицированные ошибки не оборачиваем повторно
+    var notFound models.OrderNotFoundError
+    var exists models.OrderAlreadyExistsError
+    var dbErr models.DatabaseError
+    if errors.As(err, &notFound) || errors.As(err, &exists) || errors.As(err, &dbErr) {
+        return err
+    }
+
+    return models.DatabaseError{Operation: op, Err: err}
+}
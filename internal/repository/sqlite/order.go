@@ -0,0 +1,405 @@
+package sqlite
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log/slog"
+    "net/url"
+    "strings"
+    "time"
+
+    _ "modernc.org/sqlite" // pure-Go драйвер "sqlite"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+)
+
+const (
+    orderSQL = `INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT (order_uid) DO NOTHING`
+
+    deliverySQL = `INSERT INTO deliveries (order_uid, name, phone, zip, city, address, region, email)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+    paymentSQL = `INSERT INTO payments (order_uid, "transaction", request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+    itemSQL = `INSERT INTO items (chrt_id, order_uid, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+    orderColumns = `o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id, o.delivery_service, o.shardkey, o.sm_id, o.date_created, o.oof_shard`
+)
+
+type Repository struct {
+    db *sql.DB
+}
+
+func New(db *sql.DB) *Repository {
+    return &Repository{db: db}
+}
+
+// Open открывает файл БД, создавая его при необходимости. Внешние ключи в SQLite
+// выключены по умолчанию, а busy_timeout нужен, чтобы параллельные записи
+// ждали блокировку, а не падали с SQLITE_BUSY.
+func Open(path string) (*sql.DB, error) {
+    params := url.Values{}
+    params.Add("_pragma", "foreign_keys(1)")
+    params.Add("_pragma", "journal_mode(WAL)")
+    params.Add("_pragma", "busy_timeout(5000)")
+
+    db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+    if err != nil {
+        return nil, err
+    }
+    return db, nil
+}
+
+// Время хранится в Unix-наносекундах UTC
+func toUnix(t time.Time) int64 {
+    if t.IsZero() {
+        return 0
+    }
+    return t.UnixNano()
+}
+
+func fromUnix(n int64) time.Time {
+    if n == 0 {
+        return time.Time{}
+    }
+    return time.Unix(0, n).UTC()
+}
+
+func (r *Repository) Create(ctx context.Context, order models.Order) error {
+    const op = "repository.sqlite.Create"
+
+    start := time.Now()
+    err := r.inTx(ctx, func(tx *sql.Tx) error {
+        inserted, err := insertOrder(ctx, tx, order)
+        if err != nil {
+            return err
+        }
+        if !inserted {
+            return models.OrderAlreadyExistsError{OrderUID: order.OrderUID}
+        }
+        return nil
+    })
+    metrics.DBOperationDuration.WithLabelValues("create", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    return classifyError(op, order.OrderUID, err)
+}
+
+// CreateBatch сохраняет пачку заказов в одной транзакции.
+// Дубликаты получают OrderAlreadyExistsError и не мешают сохранению остальных.
+func (r *Repository) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    const op = "repository.sqlite.CreateBatch"
+
+    if len(orders) == 0 {
+        return nil, nil
+    }
+
+    results := make([]error, len(orders))
+
+    start := time.Now()
+    err := r.inTx(ctx, func(tx *sql.Tx) error {
+        for i, order := range orders {
+            inserted, err := insertOrder(ctx, tx, order)
+            if err != nil {
+                return err
+            }
+            if !inserted {
+                results[i] = models.OrderAlreadyExistsError{OrderUID: order.OrderUID}
+            }
+        }
+        return nil
+    })
+    metrics.DBOperationDuration.WithLabelValues("create_batch", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return nil, classifyError(op, "", err)
+    }
+    return results, nil
+}
+
+// insertOrder вставляет заказ со всеми связанными данными.
+// Возвращает false, если заказ с таким order_uid уже есть.
+func insertOrder(ctx context.Context, tx *sql.Tx, order models.Order) (bool, error) {
+    res, err := tx.ExecContext(ctx, orderSQL,
+        order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature, order.CustomerID,
+        order.DeliveryService, order.Shardkey, order.SmID, toUnix(order.DateCreated), order.OofShard)
+    if err != nil {
+        return false, fmt.Errorf("insert order: %w", err)
+    }
+    if n, err := res.RowsAffected(); err != nil || n == 0 {
+        return false, err
+    }
+
+    d := order.Delivery
+    if _, err := tx.ExecContext(ctx, deliverySQL, order.OrderUID, d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email); err != nil {
+        return false, fmt.Errorf("insert delivery: %w", err)
+    }
+
+    p := order.Payment
+    if _, err := tx.ExecContext(ctx, paymentSQL, order.OrderUID, p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount, p.PaymentDt, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee); err != nil {
+        return false, fmt.Errorf("insert payment: %w", err)
+    }
+
+    for _, item := range order.Items {
+        if _, err := tx.ExecContext(ctx, itemSQL, item.ChrtID, order.OrderUID, item.TrackNumber, item.Price, item.Rid, item.Name, item.Sale, item.Size, item.TotalPrice, item.NmID, item.Brand, item.Status); err != nil {
+            return false, fmt.Errorf("insert item: %w", err)
+        }
+    }
+
+    return true, nil
+}
+
+func (r *Repository) GetByUID(ctx context.Context, uid string) (models.Order, error) {
+    const op = "repository.sqlite.GetByUID"
+
+    var orders []models.Order
+    start := time.Now()
+    err := r.inTx(ctx, func(tx *sql.Tx) error {
+        var err error
+        orders, err = queryOrders(ctx, tx, `SELECT `+orderColumns+` FROM orders o WHERE o.order_uid = ?`, uid)
+        if err == nil && len(orders) == 0 {
+            err = sql.ErrNoRows
+        }
+        return err
+    })
+    metrics.DBOperationDuration.WithLabelValues("get_by_uid", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return models.Order{}, classifyError(op, uid, err)
+    }
+    return orders[0], nil
+}
+
+func (r *Repository) GetLatest(ctx context.Context, limit int) ([]models.Order, error) {
+    const op = "repository.sqlite.GetLatest"
+
+    var orders []models.Order
+    start := time.Now()
+    err := r.inTx(ctx, func(tx *sql.Tx) error {
+        var err error
+        orders, err = queryOrders(ctx, tx, `SELECT `+orderColumns+` FROM orders o
+            ORDER BY o.date_created DESC, o.order_uid DESC
+            LIMIT ?`, limit)
+        return err
+    })
+    metrics.DBOperationDuration.WithLabelValues("get_latest", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return nil, classifyError(op, "", err)
+    }
+    return orders, nil
+}
+
+// List возвращает страницу заказов по фильтру, от новых к старым
+func (r *Repository) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    const op = "repository.sqlite.List"
+
+    if filter.Limit < 1 {
+        return models.OrderPage{}, fmt.Errorf("%s: %w", op, models.ErrInvalidLimit)
+    }
+
+    query, args := buildListQuery(filter)
+
+    var orders []models.Order
+    start := time.Now()
+    err := r.inTx(ctx, func(tx *sql.Tx) error {
+        var err error
+        orders, err = queryOrders(ctx, tx, query, args...)
+        return err
+    })
+    metrics.DBOperationDuration.WithLabelValues("list", metrics.Status(err)).Observe(time.Since(start).Seconds())
+    if err != nil {
+        return models.OrderPage{}, classifyError(op, "", err)
+    }
+
+    // Запрашивали на одну запись больше, чтобы понять, есть ли следующая страница
+    page := models.OrderPage{Orders: orders}
+    if len(orders) > filter.Limit {
+        page.Orders = orders[:filter.Limit]
+        page.NextCursor = models.CursorAfter(page.Orders[len(page.Orders)-1])
+    }
+    return page, nil
+}
+
+func buildListQuery(filter models.OrderFilter) (string, []any) {
+    var conditions []string
+    var args []any
+
+    add := func(cond string, values ...any) {
+        conditions = append(conditions, cond)
+        args = append(args, values...)
+    }
+
+    if filter.CustomerID != "" {
+        add("o.customer_id = ?", filter.CustomerID)
+    }
+    if filter.TrackNumber != "" {
+        add("o.track_number = ?", filter.TrackNumber)
+    }
+    if filter.DeliveryService != "" {
+        add("o.delivery_service = ?", filter.DeliveryService)
+    }
+    if filter.Locale != "" {
+        add("o.locale = ?", filter.Locale)
+    }
+    if filter.Currency != "" {
+        add("EXISTS (SELECT 1 FROM payments p WHERE p.order_uid = o.order_uid AND p.currency = ?)", filter.Currency)
+    }
+    if !filter.CreatedFrom.IsZero() {
+        add("o.date_created >= ?", toUnix(filter.CreatedFrom))
+    }
+    if !filter.CreatedTo.IsZero() {
+        add("o.date_created < ?", toUnix(filter.CreatedTo))
+    }
+    if filter.Cursor != nil {
+        add("(o.date_created, o.order_uid) < (?, ?)", toUnix(filter.Cursor.DateCreated), filter.Cursor.OrderUID)
+    }
+
+    query := `SELECT ` + orderColumns + ` FROM orders o`
+    if len(conditions) > 0 {
+        query += "\n            WHERE " + strings.Join(conditions, " AND ")
+    }
+    query += "\n            ORDER BY o.date_created DESC, o.order_uid DESC\n            LIMIT ?"
+    args = append(args, filter.Limit+1)
+
+    return query, args
+}
+
+// inTx выполняет fn в транзакции; чтение в транзакции видит согласованный
+// снимок заказов и связанных таблиц
+func (r *Repository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("failed to begin transaction: %w", err)
+    }
+    defer func() {
+        if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+            slog.ErrorContext(ctx, "failed to rollback transaction", "error", err)
+        }
+    }()
+
+    if err := fn(tx); err != nil {
+        return err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("failed to commit transaction: %w", err)
+    }
+    return nil
+}
+
+// queryOrders выполняет запрос к orders и подгружает delivery, payment и items найденных заказов
+func queryOrders(ctx context.Context, tx *sql.Tx, query string, args ...any) ([]models.Order, error) {
+    rows, err := tx.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("query orders: %w", err)
+    }
+    defer rows.Close()
+
+    orders := []models.Order{}
+    for rows.Next() {
+        var o models.Order
+        var dateCreated int64
+        if err := rows.Scan(&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature, &o.CustomerID, &o.DeliveryService, &o.Shardkey, &o.SmID, &dateCreated, &o.OofShard); err != nil {
+            return nil, fmt.Errorf("scan order: %w", err)
+        }
+        o.DateCreated = fromUnix(dateCreated)
+        orders = append(orders, o)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("iterate orders: %w", err)
+    }
+
+    if len(orders) == 0 {
+        return orders, nil
+    }
+
+    orderMap := make(map[string]*models.Order, len(orders))
+    orderUIDs := make([]any, 0, len(orders))
+    for i := range orders {
+        orderMap[orders[i].OrderUID] = &orders[i]
+        orderUIDs = append(orderUIDs, orders[i].OrderUID)
+    }
+
+    if err := queryDeliveryPaymentItems(ctx, tx, orderMap, orderUIDs); err != nil {
+        return nil, err
+    }
+
+    return orders, nil
+}
+
+func queryDeliveryPaymentItems(ctx context.Context, tx *sql.Tx, orderMap map[string]*models.Order, orderUIDs []any) error {
+    const op = "repository.sqlite.queryDeliveryPaymentItems"
+
+    in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(orderUIDs)), ", ") + ")"
+
+    // Delivery
+    deliveryRows, err := tx.QueryContext(ctx, `
+        SELECT order_uid, name, phone, zip, city, address, region, email
+        FROM deliveries WHERE order_uid IN `+in, orderUIDs...)
+    if err != nil {
+        return fmt.Errorf("%s: query deliveries: %w", op, err)
+    }
+    defer deliveryRows.Close()
+
+    for deliveryRows.Next() {
+        var d models.Delivery
+        var orderUID string
+        if err := deliveryRows.Scan(&orderUID, &d.Name, &d.Phone, &d.Zip, &d.City, &d.Address, &d.Region, &d.Email); err != nil {
+            return fmt.Errorf("%s: scan delivery: %w", op, err)
+        }
+        if order, ok := orderMap[orderUID]; ok {
+            order.Delivery = d
+        }
+    }
+    if err := deliveryRows.Err(); err != nil {
+        return fmt.Errorf("%s: iterate deliveries: %w", op, err)
+    }
+
+    // Payment
+    paymentRows, err := tx.QueryContext(ctx, `
+        SELECT order_uid, "transaction", request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
+        FROM payments WHERE order_uid IN `+in, orderUIDs...)
+    if err != nil {
+        return fmt.Errorf("%s: query payments: %w", op, err)
+    }
+    defer paymentRows.Close()
+
+    for paymentRows.Next() {
+        var p models.Payment
+        var orderUID string
+        if err := paymentRows.Scan(&orderUID, &p.Transaction, &p.RequestID, &p.Currency, &p.Provider, &p.Amount, &p.PaymentDt, &p.Bank, &p.DeliveryCost, &p.GoodsTotal, &p.CustomFee); err != nil {
+            return fmt.Errorf("%s: scan payment: %w", op, err)
+        }
+        if order, ok := orderMap[orderUID]; ok {
+            order.Payment = p
+        }
+    }
+    if err := paymentRows.Err(); err != nil {
+        return fmt.Errorf("%s: iterate payments: %w", op, err)
+    }
+
+    // Items
+    itemRows, err := tx.QueryContext(ctx, `
+        SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+        FROM items WHERE order_uid IN `+in+`
+        ORDER BY id`, orderUIDs...)
+    if err != nil {
+        return fmt.Errorf("%s: query items: %w", op, err)
+    }
+    defer itemRows.Close()
+
+    for itemRows.Next() {
+        var i models.Item
+        var orderUID string
+        if err := itemRows.Scan(&orderUID, &i.ChrtID, &i.TrackNumber, &i.Price, &i.Rid, &i.Name, &i.Sale, &i.Size, &i.TotalPrice, &i.NmID, &i.Brand, &i.Status); err != nil {
+            return fmt.Errorf("%s: scan item: %w", op, err)
+        }
+        if order, ok := orderMap[orderUID]; ok {
+            order.Items = append(order.Items, i)
+        }
+    }
+
+    return itemRows.Err()
+}
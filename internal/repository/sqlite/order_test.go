@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+    "context"
+    "path/filepath"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+
+    "L0/internal/migrations"
+    "L0/internal/repository"
+    "L0/internal/repository/repotest"
+)
+
+func TestRepository_Conformance(t *testing.T) {
+    repotest.Run(t, func(t *testing.T) repository.OrderRepository {
+        db, err := Open(filepath.Join(t.TempDir(), "orders.db"))
+        require.NoError(t, err)
+        t.Cleanup(func() { db.Close() })
+
+        migrator, err := migrations.NewSQLite(db)
+        require.NoError(t, err)
+        _, err = migrator.Up(context.Background())
+        require.NoError(t, err)
+
+        return New(db)
+    })
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"L0/internal/metrics"
+	"L0/internal/models"
+)
+
+// Буфер подписчика по умолчанию, если в конфиге не задано
+const defaultSubscriberBuffer = 64
+
+// OrderEvent - сохранённый заказ с порядковым номером события
+type OrderEvent struct {
+	ID    uint64
+	Order models.Order
+}
+
+// SubscribeOptions - фильтры подписки и точка, с которой её нужно возобновить
+type SubscribeOptions struct {
+	AfterID         uint64 // 0 - только новые события
+	CustomerID      string
+	DeliveryService string
+}
+
+func (o SubscribeOptions) matches(order models.Order) bool {
+	if o.CustomerID != "" && order.CustomerID != o.CustomerID {
+		return false
+	}
+	if o.DeliveryService != "" && order.DeliveryService != o.DeliveryService {
+		return false
+	}
+	return true
+}
+
+type subscriber struct {
+	ch   chan OrderEvent
+	opts SubscribeOptions
+}
+
+// broadcaster рассылает события всем подписчикам и хранит последние события
+// для возобновления подписки. Публикация никогда не блокируется: медленный
+// подписчик теряет события, а не тормозит запись заказов.
+type broadcaster struct {
+	mu      sync.Mutex
+	nextID  uint64
+	subs    map[*subscriber]struct{}
+	history []OrderEvent // кольцевой буфер, history[head] - самое старое событие
+	head    int
+	buffer  int
+}
+
+func newBroadcaster(buffer, historySize int) *broadcaster {
+	if buffer <= 0 {
+		buffer = defaultSubscriberBuffer
+	}
+	return &broadcaster{
+		subs:    make(map[*subscriber]struct{}),
+		history: make([]OrderEvent, 0, max(historySize, 0)),
+		buffer:  buffer,
+	}
+}
+
+// subscribe возвращает подходящие под фильтр события истории после opts.AfterID
+// и канал новых событий, который закрывается после отмены ctx
+func (b *broadcaster) subscribe(ctx context.Context, opts SubscribeOptions) ([]OrderEvent, <-chan OrderEvent) {
+	sub := &subscriber{ch: make(chan OrderEvent, b.buffer), opts: opts}
+
+	b.mu.Lock()
+	missed := b.since(opts)
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	metrics.OrderSubscribers.Inc()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subs, sub)
+		close(sub.ch)
+		b.mu.Unlock()
+		metrics.OrderSubscribers.Dec()
+	}()
+
+	return missed, sub.ch
+}
+
+// since возвращает события истории после opts.AfterID. ID из будущего остался
+// от предыдущего запуска сервиса - тогда отдаём всю историю.
+func (b *broadcaster) since(opts SubscribeOptions) []OrderEvent {
+	if opts.AfterID == 0 {
+		return nil
+	}
+
+	afterID := opts.AfterID
+	if afterID > b.nextID {
+		afterID = 0
+	}
+
+	var missed []OrderEvent
+	for i := range b.history {
+		event := b.history[(b.head+i)%len(b.history)]
+		if event.ID > afterID && opts.matches(event.Order) {
+			missed = append(missed, event)
+		}
+	}
+	return missed
+}
+
+func (b *broadcaster) publish(order models.Order) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := OrderEvent{ID: b.nextID, Order: order}
+	b.remember(event)
+
+	for sub := range b.subs {
+		if !sub.opts.matches(order) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			metrics.OrderEventsDropped.Inc()
+		}
+	}
+}
+
+func (b *broadcaster) remember(event OrderEvent) {
+	switch {
+	case cap(b.history) == 0:
+	case len(b.history) < cap(b.history):
+		b.history = append(b.history, event)
+	default:
+		b.history[b.head] = event
+		b.head = (b.head + 1) % len(b.history)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"L0/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventIDs(events []OrderEvent) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestBroadcaster_DeliversToAllSubscribers(t *testing.T) {
+	b := newBroadcaster(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, first := b.subscribe(ctx, SubscribeOptions{})
+	_, second := b.subscribe(ctx, SubscribeOptions{})
+
+	b.publish(models.Order{OrderUID: "uid-1"})
+
+	for _, ch := range []<-chan OrderEvent{first, second} {
+		event := <-ch
+		assert.Equal(t, uint64(1), event.ID)
+		assert.Equal(t, "uid-1", event.Order.OrderUID)
+	}
+}
+
+func TestBroadcaster_DropsEventsForSlowSubscriber(t *testing.T) {
+	const buffer = 8
+	b := newBroadcaster(buffer, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, ch := b.subscribe(ctx, SubscribeOptions{})
+
+	// Публикация не блокируется, даже когда буфер подписчика заполнен
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < buffer*2; i++ {
+			b.publish(models.Order{OrderUID: "uid"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked on slow subscriber")
+	}
+
+	assert.Len(t, ch, buffer)
+	assert.Equal(t, uint64(1), (<-ch).ID)
+}
+
+func TestBroadcaster_Filters(t *testing.T) {
+	b := newBroadcaster(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, ch := b.subscribe(ctx, SubscribeOptions{CustomerID: "alice", DeliveryService: "meest"})
+
+	b.publish(models.Order{OrderUID: "uid-1", CustomerID: "bob", DeliveryService: "meest"})
+	b.publish(models.Order{OrderUID: "uid-2", CustomerID: "alice", DeliveryService: "dhl"})
+	b.publish(models.Order{OrderUID: "uid-3", CustomerID: "alice", DeliveryService: "meest"})
+
+	require.Len(t, ch, 1)
+	event := <-ch
+	assert.Equal(t, uint64(3), event.ID)
+	assert.Equal(t, "uid-3", event.Order.OrderUID)
+}
+
+func TestBroadcaster_ResumesFromHistory(t *testing.T) {
+	b := newBroadcaster(0, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, customer := range []string{"alice", "bob", "alice", "bob", "alice"} {
+		b.publish(models.Order{CustomerID: customer})
+	}
+
+	// В истории остались только события 3, 4 и 5
+	missed, _ := b.subscribe(ctx, SubscribeOptions{AfterID: 1})
+	assert.Equal(t, []uint64{3, 4, 5}, eventIDs(missed))
+
+	missed, _ = b.subscribe(ctx, SubscribeOptions{AfterID: 3, CustomerID: "alice"})
+	assert.Equal(t, []uint64{5}, eventIDs(missed))
+
+	// Без Last-Event-ID история не нужна
+	missed, _ = b.subscribe(ctx, SubscribeOptions{})
+	assert.Empty(t, missed)
+
+	// ID из будущего выдан до перезапуска сервиса: отдаём всю историю
+	missed, _ = b.subscribe(ctx, SubscribeOptions{AfterID: 100})
+	assert.Equal(t, []uint64{3, 4, 5}, eventIDs(missed))
+
+	// Событие, опубликованное после подписки, приходит в канал, а не в историю
+	missed, ch := b.subscribe(ctx, SubscribeOptions{AfterID: 5})
+	assert.Empty(t, missed)
+	b.publish(models.Order{CustomerID: "alice"})
+	assert.Equal(t, uint64(6), (<-ch).ID)
+}
+
+func TestBroadcaster_ClosesChannelOnCancel(t *testing.T) {
+	b := newBroadcaster(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, ch := b.subscribe(ctx, SubscribeOptions{})
+	cancel()
+
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-ch:
+			return !ok
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+
+	// После отписки публикация не паникует на закрытом канале
+	b.publish(models.Order{OrderUID: "uid-1"})
+}
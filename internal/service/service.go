@@ -2,56 +2,134 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"L0/internal/config"
+	"L0/internal/metrics"
 	"L0/internal/models"
 	"L0/internal/repository"
 
-	"github.com/hashicorp/golang-lru/v2/expirable"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "L0/internal/service"
+
 type OrderService interface {
 	GetByUID(ctx context.Context, uid string) (models.Order, error)
 	Create(ctx context.Context, order models.Order) error
+	CreateBatch(ctx context.Context, orders []models.Order) ([]error, error)
 	GetLatest(ctx context.Context, limit int) ([]models.Order, error)
+	List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error)
+	WarmUpCache(ctx context.Context) error
+	// Subscribe возвращает пропущенные события из недавней истории (после opts.AfterID)
+	// и канал заказов, сохранённых после подписки. Канал закрывается после отмены ctx.
+	Subscribe(ctx context.Context, opts SubscribeOptions) ([]OrderEvent, <-chan OrderEvent)
+	// SetCacheTTL меняет время жизни записей кэша без его очистки
+	SetCacheTTL(ttl time.Duration)
 }
 
 type orderService struct {
-	repo  repository.OrderRepository
-	cache *expirable.LRU[string, models.Order]
+	repo       repository.OrderRepository
+	cache      *lru.Cache[string, cacheEntry]
+	cacheTTL   atomic.Int64 // time.Duration; меняется в SetCacheTTL
+	warmUpSize int
+	events     *broadcaster
+}
+
+// cacheEntry хранит время добавления, чтобы срок жизни проверялся по текущему TTL
+type cacheEntry struct {
+	order models.Order
+	added time.Time
 }
 
 func NewOrderService(repo repository.OrderRepository, cfg *config.Config) OrderService {
-	cache := expirable.NewLRU[string, models.Order](
-		cfg.Cache.Size,
-		nil, // без callback'а при удалении
-		cfg.Cache.TTL,
-	)
-
-	return &orderService{
-		repo:  repo,
-		cache: cache,
+	// Прогревать больше, чем помещается в кэш, бессмысленно
+	warmUpSize := min(cfg.Cache.WarmUpSize, cfg.Cache.Size)
+
+	// Ошибка возможна только при неположительном размере, а его отсекает config.Validate
+	cache, err := lru.NewWithEvict(cfg.Cache.Size, func(string, cacheEntry) { metrics.CacheEvictions.Inc() })
+	if err != nil {
+		panic(err)
 	}
+
+	s := &orderService{
+		repo:       repo,
+		cache:      cache,
+		warmUpSize: warmUpSize,
+		events:     newBroadcaster(cfg.Stream.BufferSize, cfg.Stream.HistorySize),
+	}
+	s.cacheTTL.Store(int64(cfg.Cache.TTL))
+	return s
+}
+
+// SetCacheTTL меняет TTL на лету: срок жизни каждой записи сравнивается
+// с текущим TTL при чтении, поэтому кэш не пересоздаётся и не очищается.
+// После уменьшения TTL записи старше нового значения сразу считаются устаревшими
+func (s *orderService) SetCacheTTL(ttl time.Duration) {
+	if time.Duration(s.cacheTTL.Swap(int64(ttl))) == ttl {
+		return
+	}
+	slog.Info("Cache TTL changed", "ttl", ttl.String(), "orders", s.cache.Len())
+}
+
+// cacheGet возвращает заказ из кэша, если запись не старше TTL. Устаревшая
+// запись удаляется при чтении; до этого она занимает место, пока её не
+// вытеснит LRU. TTL <= 0 - записи не устаревают
+func (s *orderService) cacheGet(uid string) (models.Order, bool) {
+	entry, ok := s.cache.Get(uid)
+	if !ok {
+		return models.Order{}, false
+	}
+	if ttl := time.Duration(s.cacheTTL.Load()); ttl > 0 && time.Since(entry.added) > ttl {
+		s.cache.Remove(uid)
+		return models.Order{}, false
+	}
+	return entry.order, true
+}
+
+func (s *orderService) cacheAdd(order models.Order) {
+	s.cache.Add(order.OrderUID, cacheEntry{order: order, added: time.Now()})
 }
 
 func (s *orderService) GetByUID(ctx context.Context, uid string) (models.Order, error) {
-	if order, exists := s.cache.Get(uid); exists {
-		slog.Info("Order found in cache", "order_uid", uid)
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "orderService.GetByUID", trace.WithAttributes(attribute.String("order.uid", uid)))
+	defer span.End()
+
+	if order, exists := s.cacheGet(uid); exists {
+		metrics.CacheHits.Inc()
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		slog.InfoContext(ctx, "Order found in cache", "order_uid", uid)
 		return order, nil
 	}
 
-	slog.Info("Cache miss, querying database", "order_uid", uid)
+	metrics.CacheMisses.Inc()
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+	slog.InfoContext(ctx, "Cache miss, querying database", "order_uid", uid)
 
+	// Ошибки репозитория уже типизированы: OrderNotFoundError отличается от DatabaseError
 	order, err := s.repo.GetByUID(ctx, uid)
 	if err != nil {
-		slog.Error("Order not found in database", "order_uid", uid, "error", err)
-		return models.Order{}, models.OrderNotFoundError{OrderUID: uid}
+		var notFoundErr models.OrderNotFoundError
+		if errors.As(err, &notFoundErr) {
+			slog.InfoContext(ctx, "Order not found in database", "order_uid", uid)
+		} else {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			slog.ErrorContext(ctx, "Failed to get order from database", "order_uid", uid, "error", err)
+		}
+		return models.Order{}, err
 	}
 
-	slog.Info("Order found in database, adding to cache", "order_uid", uid)
+	slog.InfoContext(ctx, "Order found in database, adding to cache", "order_uid", uid)
 
-	s.cache.Add(uid, order)
+	s.cacheAdd(order)
 
 	return order, nil
 }
@@ -61,13 +139,68 @@ func (s *orderService) Create(ctx context.Context, order models.Order) error {
 		return err
 	}
 
-	slog.Info("Order created, adding to cache", "order_uid", order.OrderUID)
+	slog.InfoContext(ctx, "Order created, adding to cache", "order_uid", order.OrderUID)
 
-	s.cache.Add(order.OrderUID, order)
+	s.cacheAdd(order)
+	s.events.publish(order)
 
 	return nil
 }
 
+// CreateBatch сохраняет пачку заказов и добавляет сохранённые в кэш.
+// Ошибки по отдельным заказам возвращаются в слайсе в порядке orders.
+func (s *orderService) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+	results, err := s.repo.CreateBatch(ctx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	stored := 0
+	for i, order := range orders {
+		if results[i] == nil {
+			s.cacheAdd(order)
+			s.events.publish(order)
+			stored++
+		}
+	}
+
+	slog.InfoContext(ctx, "Orders batch created, adding to cache", "orders", len(orders), "stored", stored)
+
+	return results, nil
+}
+
 func (s *orderService) GetLatest(ctx context.Context, limit int) ([]models.Order, error) {
 	return s.repo.GetLatest(ctx, limit)
 }
+
+func (s *orderService) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *orderService) Subscribe(ctx context.Context, opts SubscribeOptions) ([]OrderEvent, <-chan OrderEvent) {
+	return s.events.subscribe(ctx, opts)
+}
+
+// WarmUpCache загружает в кэш последние заказы из БД
+func (s *orderService) WarmUpCache(ctx context.Context) error {
+	if s.warmUpSize <= 0 {
+		slog.Info("Cache warm-up disabled")
+		return nil
+	}
+
+	start := time.Now()
+
+	orders, err := s.repo.GetLatest(ctx, s.warmUpSize)
+	if err != nil {
+		return err
+	}
+
+	// Добавляем от старых к новым, чтобы самые свежие заказы вытеснялись последними
+	for i := len(orders) - 1; i >= 0; i-- {
+		s.cacheAdd(orders[i])
+	}
+
+	slog.Info("Cache warmed up", "orders", len(orders), "duration", time.Since(start).String())
+
+	return nil
+}
@@ -31,6 +31,12 @@ func (m *MockOrderRepository) Create(ctx context.Context, order models.Order) er
     return args.Error(0)
 }
 
+func (m *MockOrderRepository) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    args := m.Called(ctx, orders)
+    results, _ := args.Get(0).([]error)
+    return results, args.Error(1)
+}
+
 func (m *MockOrderRepository) GetByUID(ctx context.Context, uid string) (models.Order, error) {
     args := m.Called(ctx, uid)
     return args.Get(0).(models.Order), args.Error(1)
@@ -41,6 +47,11 @@ func (m *MockOrderRepository) GetLatest(ctx context.Context, limit int) ([]model
     return args.Get(0).([]models.Order), args.Error(1)
 }
 
+func (m *MockOrderRepository) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    args := m.Called(ctx, filter)
+    return args.Get(0).(models.OrderPage), args.Error(1)
+}
+
 func TestOrderService_GetByUID_FromCache(t *testing.T) {
     mockRepo := &MockOrderRepository{}
     cfg := createTestConfig()
@@ -68,7 +79,7 @@ func TestOrderService_GetByUID_FromRepository(t *testing.T) {
     testOrder := models.Order{OrderUID: "test-uid"}
     ctx := context.Background()
 
-    mockRepo.On("GetByUID", ctx, "test-uid").Return(testOrder, nil).Once()
+    mockRepo.On("GetByUID", mock.Anything, "test-uid").Return(testOrder, nil).Once()
 
     result, err := service.GetByUID(ctx, "test-uid")
     assert.NoError(t, err)
@@ -84,8 +95,8 @@ func TestOrderService_GetByUID_OrderNotFound(t *testing.T) {
 
     ctx := context.Background()
 
-    // Проверяем, что возвращается кастомная ошибка, если заказ не найден
-    mockRepo.On("GetByUID", ctx, "non-existent-uid").Return(models.Order{}, errors.New("not found")).Once()
+    // Проверяем, что кастомная ошибка репозитория доходит до вызывающего
+    mockRepo.On("GetByUID", mock.Anything, "non-existent-uid").Return(models.Order{}, models.OrderNotFoundError{OrderUID: "non-existent-uid"}).Once()
 
     _, err := service.GetByUID(ctx, "non-existent-uid")
     assert.Error(t, err)
@@ -95,6 +106,24 @@ func TestOrderService_GetByUID_OrderNotFound(t *testing.T) {
     mockRepo.AssertExpectations(t)
 }
 
+func TestOrderService_GetByUID_DatabaseError(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    cfg := createTestConfig()
+    service := NewOrderService(mockRepo, cfg)
+
+    ctx := context.Background()
+
+    // Недоступная БД не должна превращаться в "заказ не найден"
+    dbErr := models.DatabaseError{Operation: "get", Err: errors.New("connection refused")}
+    mockRepo.On("GetByUID", mock.Anything, "test-uid").Return(models.Order{}, dbErr).Once()
+
+    _, err := service.GetByUID(ctx, "test-uid")
+    assert.Error(t, err)
+    assert.IsType(t, models.DatabaseError{}, err)
+
+    mockRepo.AssertExpectations(t)
+}
+
 func TestOrderService_Create_Success(t *testing.T) {
     mockRepo := &MockOrderRepository{}
     cfg := createTestConfig()
@@ -115,6 +144,28 @@ func TestOrderService_Create_Success(t *testing.T) {
     assert.Equal(t, testOrder.OrderUID, cachedOrder.OrderUID)
 }
 
+func TestOrderService_Create_PublishesOnlyStoredOrders(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    cfg := createTestConfig()
+    service := NewOrderService(mockRepo, cfg)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    _, events := service.Subscribe(ctx, SubscribeOptions{})
+
+    stored := models.Order{OrderUID: "stored-uid"}
+    failed := models.Order{OrderUID: "failed-uid"}
+    mockRepo.On("Create", ctx, failed).Return(models.OrderAlreadyExistsError{OrderUID: "failed-uid"}).Once()
+    mockRepo.On("Create", ctx, stored).Return(nil).Once()
+
+    assert.Error(t, service.Create(ctx, failed))
+    assert.NoError(t, service.Create(ctx, stored))
+
+    event := <-events
+    assert.Equal(t, "stored-uid", event.Order.OrderUID)
+    assert.Empty(t, events)
+}
+
 func TestOrderService_Create_Error(t *testing.T) {
     mockRepo := &MockOrderRepository{}
     cfg := createTestConfig()
@@ -153,4 +204,126 @@ func TestOrderService_GetLatest(t *testing.T) {
     assert.Equal(t, "uid2", result[1].OrderUID)
 
     mockRepo.AssertExpectations(t)
+}
+
+func TestOrderService_WarmUpCache(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    cfg := createTestConfig()
+    cfg.Cache.WarmUpSize = 5000 // больше размера кэша
+    service := NewOrderService(mockRepo, cfg)
+
+    orders := []models.Order{
+        {OrderUID: "uid2"},
+        {OrderUID: "uid1"},
+    }
+    ctx := context.Background()
+
+    mockRepo.On("GetLatest", ctx, cfg.Cache.Size).Return(orders, nil).Once()
+
+    err := service.WarmUpCache(ctx)
+    assert.NoError(t, err)
+
+    // Заказы отдаются из кэша без обращения к репозиторию
+    for _, uid := range []string{"uid1", "uid2"} {
+        result, err := service.GetByUID(ctx, uid)
+        assert.NoError(t, err)
+        assert.Equal(t, uid, result.OrderUID)
+    }
+
+    mockRepo.AssertExpectations(t)
+    mockRepo.AssertNotCalled(t, "GetByUID", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_WarmUpCache_Disabled(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    cfg := createTestConfig()
+    cfg.Cache.WarmUpSize = 0
+    service := NewOrderService(mockRepo, cfg)
+
+    err := service.WarmUpCache(context.Background())
+    assert.NoError(t, err)
+
+    mockRepo.AssertNotCalled(t, "GetLatest", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_CreateBatch_CachesStoredOrders(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    cfg := createTestConfig()
+    service := NewOrderService(mockRepo, cfg)
+
+    orders := []models.Order{{OrderUID: "uid1"}, {OrderUID: "uid2"}}
+    ctx := context.Background()
+
+    mockRepo.On("CreateBatch", ctx, orders).
+        Return([]error{nil, models.OrderAlreadyExistsError{OrderUID: "uid2"}}, nil).Once()
+    mockRepo.On("GetByUID", mock.Anything, "uid2").Return(models.Order{OrderUID: "uid2"}, nil).Once()
+
+    results, err := service.CreateBatch(ctx, orders)
+    assert.NoError(t, err)
+    assert.Len(t, results, 2)
+    assert.NoError(t, results[0])
+    assert.IsType(t, models.OrderAlreadyExistsError{}, results[1])
+
+    // Сохранённый заказ в кэше, дубликат - нет
+    _, err = service.GetByUID(ctx, "uid1")
+    assert.NoError(t, err)
+    _, err = service.GetByUID(ctx, "uid2")
+    assert.NoError(t, err)
+
+    mockRepo.AssertExpectations(t)
+    mockRepo.AssertNumberOfCalls(t, "GetByUID", 1)
+}
+
+func TestOrderService_SetCacheTTL_KeepsCachedOrders(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    cfg := createTestConfig()
+    cfg.Cache.Size = 2
+    svc := NewOrderService(mockRepo, cfg).(*orderService)
+    ctx := context.Background()
+
+    for _, uid := range []string{"order-1", "order-2"} {
+        order := models.Order{OrderUID: uid}
+        mockRepo.On("Create", ctx, order).Return(nil).Once()
+        assert.NoError(t, svc.Create(ctx, order))
+    }
+    cache := svc.cache
+
+    svc.SetCacheTTL(time.Hour)
+
+    assert.Equal(t, time.Hour, time.Duration(svc.cacheTTL.Load()))
+    assert.Same(t, cache, svc.cache, "cache is not rebuilt")
+    assert.Equal(t, []string{"order-1", "order-2"}, svc.cache.Keys(), "LRU order is preserved")
+
+    // Самый старый заказ по-прежнему вытесняется первым
+    order := models.Order{OrderUID: "order-3"}
+    mockRepo.On("Create", ctx, order).Return(nil).Once()
+    assert.NoError(t, svc.Create(ctx, order))
+    assert.Equal(t, []string{"order-2", "order-3"}, svc.cache.Keys())
+
+    _, err := svc.GetByUID(ctx, "order-2")
+    assert.NoError(t, err)
+    mockRepo.AssertNotCalled(t, "GetByUID", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_SetCacheTTL_ExpiresOlderOrders(t *testing.T) {
+    mockRepo := &MockOrderRepository{}
+    svc := NewOrderService(mockRepo, createTestConfig()).(*orderService)
+    ctx := context.Background()
+
+    order := models.Order{OrderUID: "order-1"}
+    mockRepo.On("Create", ctx, order).Return(nil).Once()
+    assert.NoError(t, svc.Create(ctx, order))
+
+    time.Sleep(20 * time.Millisecond)
+    svc.SetCacheTTL(10 * time.Millisecond)
+
+    // Запись старше нового TTL больше не отдаётся из кэша
+    mockRepo.On("GetByUID", mock.Anything, "order-1").Return(order, nil).Once()
+    _, err := svc.GetByUID(ctx, "order-1")
+    assert.NoError(t, err)
+
+    // Заново добавленная запись живёт по новому TTL
+    _, err = svc.GetByUID(ctx, "order-1")
+    assert.NoError(t, err)
+    mockRepo.AssertNumberOfCalls(t, "GetByUID", 1)
 }
\ No newline at end of file
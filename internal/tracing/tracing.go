@@ -0,0 +1,80 @@
+// Package tracing настраивает OpenTelemetry: экспорт span'ов, распространение
+// контекста через заголовки Kafka и trace_id/span_id в логах slog
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"L0/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// Экспортёры TRACING_EXPORTER
+const (
+	ExporterNone   = "none"   // span'ы не создаются
+	ExporterOTLP   = "otlp"   // OTLP/gRPC на TRACING_ENDPOINT
+	ExporterStdout = "stdout" // JSON в stdout, для локальной отладки
+)
+
+// Setup регистрирует глобальный TracerProvider и пропагатор W3C Trace Context.
+// Возвращённая функция дописывает накопленные span'ы и останавливает экспорт,
+// её нужно вызвать при остановке сервиса
+func Setup(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	// Контекст из входящих запросов и сообщений передаётся дальше даже без экспорта
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, err := newExporter(ctx, cfg, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg config.Tracing, stdout io.Writer) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return nil, nil
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(stdout))
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("create OTLP exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected %s, %s or %s", cfg.Exporter, ExporterOTLP, ExporterStdout, ExporterNone)
+	}
+}
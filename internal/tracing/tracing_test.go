@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"L0/internal/config"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func testTracer(t *testing.T) trace.Tracer {
+	t.Helper()
+
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	return sdktrace.NewTracerProvider().Tracer("test")
+}
+
+func TestKafkaHeaders_RoundTrip(t *testing.T) {
+	tracer := testTracer(t)
+
+	ctx, span := tracer.Start(context.Background(), "send")
+	defer span.End()
+
+	m := kafka.Message{Headers: []kafka.Header{{Key: "x-other", Value: []byte("1")}}}
+	InjectKafka(ctx, &m)
+	InjectKafka(ctx, &m) // повторная публикация не дублирует заголовок
+
+	assert.Len(t, m.Headers, 2)
+
+	extracted := trace.SpanContextFromContext(ExtractKafka(context.Background(), m))
+	assert.True(t, extracted.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+
+	assert.False(t, trace.SpanContextFromContext(ExtractKafka(context.Background(), kafka.Message{})).IsValid())
+}
+
+func TestLogHandler_AddsTraceIDs(t *testing.T) {
+	tracer := testTracer(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	ctx, span := tracer.Start(context.Background(), "op")
+	logger.InfoContext(ctx, "with span")
+	span.End()
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+	assert.Equal(t, "test", record["component"])
+
+	buf.Reset()
+	logger.Info("without span")
+	record = nil
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.NotContains(t, record, "trace_id")
+}
+
+func TestNewExporter(t *testing.T) {
+	var out bytes.Buffer
+
+	exporter, err := newExporter(context.Background(), config.Tracing{Exporter: ExporterNone}, &out)
+	require.NoError(t, err)
+	assert.Nil(t, exporter)
+
+	exporter, err = newExporter(context.Background(), config.Tracing{Exporter: ExporterStdout}, &out)
+	require.NoError(t, err)
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	_, span := provider.Tracer("test").Start(context.Background(), "exported")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+	assert.Contains(t, out.String(), `"Name":"exported"`)
+
+	_, err = newExporter(context.Background(), config.Tracing{Exporter: "jaeger"}, &out)
+	assert.Error(t, err)
+}
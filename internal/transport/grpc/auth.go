@@ -0,0 +1,87 @@
+package grpc
+
+import (
+    "context"
+    "errors"
+    "log/slog"
+    "strings"
+
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+// Все методы сервиса только читают заказы, поэтому достаточно роли viewer,
+// как для GET-маршрутов HTTP. Reflection тоже закрыт ключом
+const requiredRole = models.RoleViewer
+
+// authenticate - аналог Authenticator.Handler и RequireRole для gRPC. Ключ
+// берётся из метаданных x-api-key или authorization: Bearer <key>
+func authenticate(ctx context.Context, auth *mw.Authenticator) (context.Context, error) {
+    raw := metadataAPIKey(ctx)
+    if raw == "" {
+        return nil, status.Error(codes.Unauthenticated, "api key is required")
+    }
+
+    ctx, err := auth.Authenticate(ctx, raw)
+    if errors.Is(err, models.ErrAPIKeyNotFound) {
+        return nil, status.Error(codes.Unauthenticated, "invalid api key")
+    }
+    if err != nil {
+        slog.ErrorContext(ctx, "failed to check api key", "error", err)
+        return nil, status.Error(codes.Unavailable, "storage is temporarily unavailable")
+    }
+
+    key, _ := mw.APIKeyFromContext(ctx)
+    if !key.Role.Allows(requiredRole) {
+        return nil, status.Errorf(codes.PermissionDenied, "role %s is required", requiredRole)
+    }
+    return ctx, nil
+}
+
+func metadataAPIKey(ctx context.Context) string {
+    md, _ := metadata.FromIncomingContext(ctx)
+    for _, auth := range md.Get("authorization") {
+        if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+            return strings.TrimSpace(token)
+        }
+    }
+    if keys := md.Get("x-api-key"); len(keys) > 0 {
+        return keys[0]
+    }
+    return ""
+}
+
+func authUnary(auth *mw.Authenticator) grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+        ctx, err := authenticate(ctx, auth)
+        if err != nil {
+            return nil, err
+        }
+        return handler(ctx, req)
+    }
+}
+
+func authStream(auth *mw.Authenticator) grpc.StreamServerInterceptor {
+    return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        ctx, err := authenticate(ss.Context(), auth)
+        if err != nil {
+            return err
+        }
+        return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+    }
+}
+
+// authenticatedStream отдаёт обработчику контекст с владельцем ключа
+type authenticatedStream struct {
+    grpc.ServerStream
+    ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+    return s.ctx
+}
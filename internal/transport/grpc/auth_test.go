@@ -0,0 +1,96 @@
+package grpc
+
+import (
+    "context"
+    "testing"
+
+    ordersv1 "L0/api/orders/v1"
+    "L0/internal/config"
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+)
+
+func newTestAuthenticator(t *testing.T) *mw.Authenticator {
+    t.Helper()
+    auth, err := mw.NewAuthenticator(config.Auth{Keys: config.APIKeys{{Name: "dashboard", Role: "viewer", Key: "viewer-key"}}}, nil)
+    require.NoError(t, err)
+    return auth
+}
+
+func TestAuth_Unary(t *testing.T) {
+    mockService := &MockOrderService{}
+    mockService.On("GetByUID", mock.Anything, "uid-1").Return(models.Order{OrderUID: "uid-1"}, nil)
+
+    client, _ := startServer(t, mockService, Options{Auth: newTestAuthenticator(t)})
+    req := &ordersv1.GetOrderRequest{OrderUid: "uid-1"}
+
+    tests := map[string]struct {
+        md   metadata.MD
+        code codes.Code
+    }{
+        "no key":        {md: metadata.MD{}, code: codes.Unauthenticated},
+        "invalid key":   {md: metadata.Pairs("x-api-key", "wrong"), code: codes.Unauthenticated},
+        "x-api-key":     {md: metadata.Pairs("x-api-key", "viewer-key"), code: codes.OK},
+        "bearer token":  {md: metadata.Pairs("authorization", "Bearer viewer-key"), code: codes.OK},
+        "basic scheme":  {md: metadata.Pairs("authorization", "Basic viewer-key"), code: codes.Unauthenticated},
+    }
+    for name, tt := range tests {
+        t.Run(name, func(t *testing.T) {
+            ctx := metadata.NewOutgoingContext(context.Background(), tt.md)
+            _, err := client.GetOrder(ctx, req)
+            assert.Equal(t, tt.code, status.Code(err))
+        })
+    }
+}
+
+func TestAuth_Stream(t *testing.T) {
+    client, _ := startServer(t, &MockOrderService{}, Options{Auth: newTestAuthenticator(t)})
+
+    stream, err := client.WatchOrders(context.Background(), &ordersv1.WatchOrdersRequest{})
+    require.NoError(t, err)
+
+    _, err = stream.Recv()
+    assert.Equal(t, codes.Unauthenticated, status.Code(err), "stream is rejected before Subscribe")
+}
+
+func TestAuth_MasksPersonalDataByRole(t *testing.T) {
+    auth, err := mw.NewAuthenticator(config.Auth{Keys: config.APIKeys{
+        {Name: "dashboard", Role: "viewer", Key: "viewer-key"},
+        {Name: "ops", Role: "support", Key: "support-key"},
+    }}, nil)
+    require.NoError(t, err)
+
+    order := models.Order{
+        OrderUID: "uid-1",
+        Delivery: models.Delivery{Name: "Test Testov", Phone: "+9720000000"},
+        Payment:  models.Payment{Transaction: "uid-1"},
+    }
+    mockService := &MockOrderService{}
+    mockService.On("GetByUID", mock.Anything, "uid-1").Return(order, nil)
+    mockService.On("GetLatest", mock.Anything, 20).Return([]models.Order{order}, nil)
+
+    client, _ := startServer(t, mockService, Options{Auth: auth})
+    withKey := func(key string) context.Context {
+        return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+    }
+
+    resp, err := client.GetOrder(withKey("viewer-key"), &ordersv1.GetOrderRequest{OrderUid: "uid-1"})
+    require.NoError(t, err)
+    assert.Equal(t, "T***", resp.GetOrder().GetDelivery().GetName())
+    assert.Equal(t, "+972***0000", resp.GetOrder().GetDelivery().GetPhone())
+
+    latest, err := client.GetLatestOrders(withKey("viewer-key"), &ordersv1.GetLatestOrdersRequest{})
+    require.NoError(t, err)
+    assert.Equal(t, "T***", latest.GetOrders()[0].GetDelivery().GetName())
+
+    resp, err = client.GetOrder(withKey("support-key"), &ordersv1.GetOrderRequest{OrderUid: "uid-1"})
+    require.NoError(t, err)
+    assert.Equal(t, "Test Testov", resp.GetOrder().GetDelivery().GetName())
+}
@@ -0,0 +1,63 @@
+package grpc
+
+import (
+    ordersv1 "L0/api/orders/v1"
+    "L0/internal/models"
+
+    "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toProtoOrder(o models.Order) *ordersv1.Order {
+    items := make([]*ordersv1.Item, 0, len(o.Items))
+    for _, i := range o.Items {
+        items = append(items, &ordersv1.Item{
+            ChrtId:      i.ChrtID,
+            TrackNumber: i.TrackNumber,
+            Price:       int64(i.Price),
+            Rid:         i.Rid,
+            Name:        i.Name,
+            Sale:        int64(i.Sale),
+            Size:        i.Size,
+            TotalPrice:  int64(i.TotalPrice),
+            NmId:        i.NmID,
+            Brand:       i.Brand,
+            Status:      int64(i.Status),
+        })
+    }
+
+    return &ordersv1.Order{
+        OrderUid:    o.OrderUID,
+        TrackNumber: o.TrackNumber,
+        Entry:       o.Entry,
+        Delivery: &ordersv1.Delivery{
+            Name:    o.Delivery.Name,
+            Phone:   o.Delivery.Phone,
+            Zip:     o.Delivery.Zip,
+            City:    o.Delivery.City,
+            Address: o.Delivery.Address,
+            Region:  o.Delivery.Region,
+            Email:   o.Delivery.Email,
+        },
+        Payment: &ordersv1.Payment{
+            Transaction:  o.Payment.Transaction,
+            RequestId:    o.Payment.RequestID,
+            Currency:     o.Payment.Currency,
+            Provider:     o.Payment.Provider,
+            Amount:       int64(o.Payment.Amount),
+            PaymentDt:    o.Payment.PaymentDt,
+            Bank:         o.Payment.Bank,
+            DeliveryCost: int64(o.Payment.DeliveryCost),
+            GoodsTotal:   int64(o.Payment.GoodsTotal),
+            CustomFee:    int64(o.Payment.CustomFee),
+        },
+        Items:             items,
+        Locale:            o.Locale,
+        InternalSignature: o.InternalSignature,
+        CustomerId:        o.CustomerID,
+        DeliveryService:   o.DeliveryService,
+        Shardkey:          o.Shardkey,
+        SmId:              int64(o.SmID),
+        DateCreated:       timestamppb.New(o.DateCreated),
+        OofShard:          o.OofShard,
+    }
+}
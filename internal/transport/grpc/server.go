@@ -0,0 +1,229 @@
+package grpc
+
+import (
+    "context"
+    "errors"
+    "log/slog"
+    "net"
+    "time"
+
+    ordersv1 "L0/api/orders/v1"
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+    "L0/internal/pii"
+    "L0/internal/service"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/reflection"
+    "google.golang.org/grpc/status"
+)
+
+const (
+    defaultLatestLimit = 20
+    maxLatestLimit     = 100
+)
+
+// Server - gRPC сервер заказов поверх service.OrderService
+type Server struct {
+    grpc *grpc.Server
+    done chan struct{} // закрывается при остановке, чтобы завершить WatchOrders
+}
+
+// Options - необязательные зависимости сервера
+type Options struct {
+    Auth   *mw.Authenticator // nil - без аутентификации
+    Masker *pii.Masker       // nil - правила PII по умолчанию
+}
+
+func NewServer(srv service.OrderService, opts Options) *Server {
+    s := &Server{done: make(chan struct{})}
+
+    unary := []grpc.UnaryServerInterceptor{unaryLogger}
+    stream := []grpc.StreamServerInterceptor{streamLogger}
+    if opts.Auth != nil {
+        unary = append(unary, authUnary(opts.Auth))
+        stream = append(stream, authStream(opts.Auth))
+    }
+
+    s.grpc = grpc.NewServer(
+        grpc.ChainUnaryInterceptor(unary...),
+        grpc.ChainStreamInterceptor(stream...),
+    )
+    masker := opts.Masker
+    if masker == nil {
+        masker = defaultMasker
+    }
+
+    ordersv1.RegisterOrderServiceServer(s.grpc, &orderServer{service: srv, masker: masker, done: s.done})
+    reflection.Register(s.grpc)
+
+    return s
+}
+
+func (s *Server) Serve(lis net.Listener) error {
+    return s.grpc.Serve(lis)
+}
+
+// Shutdown завершает открытые стримы WatchOrders и ждёт окончания остальных
+// вызовов. Если ctx истёк раньше, оставшиеся соединения закрываются принудительно.
+func (s *Server) Shutdown(ctx context.Context) error {
+    close(s.done)
+
+    stopped := make(chan struct{})
+    go func() {
+        s.grpc.GracefulStop()
+        close(stopped)
+    }()
+
+    select {
+    case <-stopped:
+        return nil
+    case <-ctx.Done():
+        s.grpc.Stop()
+        return ctx.Err()
+    }
+}
+
+type orderServer struct {
+    ordersv1.UnimplementedOrderServiceServer
+    service service.OrderService
+    masker  *pii.Masker
+    done    <-chan struct{}
+}
+
+// defaultMasker - правила по умолчанию, как у HTTP обработчика без PII_MASK_RULES
+var defaultMasker = func() *pii.Masker {
+    m, err := pii.NewMasker("", string(models.RoleSupport))
+    if err != nil {
+        panic(err)
+    }
+    return m
+}()
+
+// view готовит заказ к отдаче так же, как orderView в HTTP: без
+// аутентификации заказ отдаётся как есть, иначе персональные данные
+// скрываются по правилам PII_MASK_RULES для роли ключа
+func (s *orderServer) view(ctx context.Context) func(models.Order) *ordersv1.Order {
+    key, ok := mw.APIKeyFromContext(ctx)
+    if !ok {
+        return toProtoOrder
+    }
+    return func(order models.Order) *ordersv1.Order { return toProtoOrder(s.masker.Mask(order, key.Role)) }
+}
+
+func (s *orderServer) GetOrder(ctx context.Context, req *ordersv1.GetOrderRequest) (*ordersv1.GetOrderResponse, error) {
+    if req.GetOrderUid() == "" {
+        return nil, status.Error(codes.InvalidArgument, "order_uid is required")
+    }
+
+    order, err := s.service.GetByUID(ctx, req.GetOrderUid())
+    if err != nil {
+        return nil, toStatus(ctx, err, "order_uid", req.GetOrderUid())
+    }
+
+    return &ordersv1.GetOrderResponse{Order: s.view(ctx)(order)}, nil
+}
+
+func (s *orderServer) GetLatestOrders(ctx context.Context, req *ordersv1.GetLatestOrdersRequest) (*ordersv1.GetLatestOrdersResponse, error) {
+    limit := int(req.GetLimit())
+    if limit == 0 {
+        limit = defaultLatestLimit
+    }
+    if limit < 1 || limit > maxLatestLimit {
+        return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxLatestLimit)
+    }
+
+    orders, err := s.service.GetLatest(ctx, limit)
+    if err != nil {
+        return nil, toStatus(ctx, err, "limit", limit)
+    }
+
+    view := s.view(ctx)
+    resp := &ordersv1.GetLatestOrdersResponse{Orders: make([]*ordersv1.Order, 0, len(orders))}
+    for _, o := range orders {
+        resp.Orders = append(resp.Orders, view(o))
+    }
+    return resp, nil
+}
+
+func (s *orderServer) WatchOrders(req *ordersv1.WatchOrdersRequest, stream ordersv1.OrderService_WatchOrdersServer) error {
+    ctx, cancel := context.WithCancel(stream.Context())
+    defer cancel()
+
+    view := s.view(ctx)
+    send := func(event service.OrderEvent) error {
+        return stream.Send(&ordersv1.WatchOrdersResponse{Order: view(event.Order), EventId: event.ID})
+    }
+
+    missed, events := s.service.Subscribe(ctx, service.SubscribeOptions{
+        AfterID:         req.GetAfterEventId(),
+        CustomerID:      req.GetCustomerId(),
+        DeliveryService: req.GetDeliveryService(),
+    })
+    for _, event := range missed {
+        if err := send(event); err != nil {
+            return err
+        }
+    }
+
+    for {
+        select {
+        case <-s.done:
+            return status.Error(codes.Unavailable, "server is shutting down")
+        case event, ok := <-events:
+            if !ok {
+                return nil
+            }
+            if err := send(event); err != nil {
+                return err
+            }
+        }
+    }
+}
+
+// toStatus сопоставляет ошибку сервиса с gRPC кодом, как errorStatus в HTTP транспорте.
+// Текст ошибок хранилища и внутренних ошибок клиенту не отдаётся, он пишется в лог
+// вместе с методом и args
+func toStatus(ctx context.Context, err error, args ...any) error {
+    var notFoundErr models.OrderNotFoundError
+    var existsErr models.OrderAlreadyExistsError
+    var dbErr models.DatabaseError
+
+    switch {
+    case errors.As(err, &notFoundErr):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.As(err, &existsErr):
+        return status.Error(codes.AlreadyExists, err.Error())
+    case errors.As(err, &dbErr):
+        logFailure(ctx, err, args)
+        return status.Error(codes.Unavailable, "storage is temporarily unavailable")
+    case errors.Is(err, context.Canceled):
+        return status.Error(codes.Canceled, err.Error())
+    case errors.Is(err, context.DeadlineExceeded):
+        return status.Error(codes.DeadlineExceeded, err.Error())
+    default:
+        logFailure(ctx, err, args)
+        return status.Error(codes.Internal, "internal server error")
+    }
+}
+
+func logFailure(ctx context.Context, err error, args []any) {
+    method, _ := grpc.Method(ctx)
+    slog.ErrorContext(ctx, "gRPC request failed", append([]any{"error", err, "method", method}, args...)...)
+}
+
+func unaryLogger(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+    start := time.Now()
+    resp, err := handler(ctx, req)
+    slog.Info("gRPC request", "method", info.FullMethod, "code", status.Code(err).String(), "duration", time.Since(start).String())
+    return resp, err
+}
+
+func streamLogger(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+    start := time.Now()
+    slog.Info("gRPC stream opened", "method", info.FullMethod)
+    err := handler(srv, ss)
+    slog.Info("gRPC stream closed", "method", info.FullMethod, "code", status.Code(err).String(), "duration", time.Since(start).String())
+    return err
+}
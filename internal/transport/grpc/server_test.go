@@ -0,0 +1,211 @@
+package grpc
+
+import (
+    "context"
+    "errors"
+    "net"
+    "testing"
+    "time"
+
+    ordersv1 "L0/api/orders/v1"
+    "L0/internal/models"
+    "L0/internal/service"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/status"
+    "google.golang.org/grpc/test/bufconn"
+)
+
+// MockOrderService - мок для сервиса заказов
+type MockOrderService struct {
+    mock.Mock
+}
+
+var _ service.OrderService = (*MockOrderService)(nil)
+
+func (m *MockOrderService) GetByUID(ctx context.Context, uid string) (models.Order, error) {
+    args := m.Called(ctx, uid)
+    order, _ := args.Get(0).(models.Order)
+    return order, args.Error(1)
+}
+
+func (m *MockOrderService) Create(ctx context.Context, order models.Order) error {
+    args := m.Called(ctx, order)
+    return args.Error(0)
+}
+
+func (m *MockOrderService) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    args := m.Called(ctx, orders)
+    results, _ := args.Get(0).([]error)
+    return results, args.Error(1)
+}
+
+func (m *MockOrderService) GetLatest(ctx context.Context, limit int) ([]models.Order, error) {
+    args := m.Called(ctx, limit)
+    orders, _ := args.Get(0).([]models.Order)
+    return orders, args.Error(1)
+}
+
+func (m *MockOrderService) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    args := m.Called(ctx, filter)
+    page, _ := args.Get(0).(models.OrderPage)
+    return page, args.Error(1)
+}
+
+func (m *MockOrderService) WarmUpCache(ctx context.Context) error {
+    args := m.Called(ctx)
+    return args.Error(0)
+}
+
+func (m *MockOrderService) SetCacheTTL(ttl time.Duration) {
+    m.Called(ttl)
+}
+
+func (m *MockOrderService) Subscribe(ctx context.Context, opts service.SubscribeOptions) ([]service.OrderEvent, <-chan service.OrderEvent) {
+    args := m.Called(ctx, opts)
+    missed, _ := args.Get(0).([]service.OrderEvent)
+    ch, _ := args.Get(1).(<-chan service.OrderEvent)
+    return missed, ch
+}
+
+// startServer поднимает сервер на bufconn и возвращает клиента
+func startServer(t *testing.T, srv service.OrderService, opts ...Options) (ordersv1.OrderServiceClient, *Server) {
+    t.Helper()
+
+    var o Options
+    if len(opts) > 0 {
+        o = opts[0]
+    }
+
+    lis := bufconn.Listen(1 << 20)
+    server := NewServer(srv, o)
+    go func() { _ = server.Serve(lis) }()
+
+    conn, err := grpc.NewClient("passthrough:///bufnet",
+        grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+        grpc.WithTransportCredentials(insecure.NewCredentials()),
+    )
+    require.NoError(t, err)
+
+    t.Cleanup(func() {
+        conn.Close()
+        ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+        defer cancel()
+        select {
+        case <-server.done:
+        default:
+            _ = server.Shutdown(ctx)
+        }
+    })
+
+    return ordersv1.NewOrderServiceClient(conn), server
+}
+
+func TestGetOrder(t *testing.T) {
+    created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+    order := models.Order{
+        OrderUID:    "uid-1",
+        DateCreated: created,
+        Payment:     models.Payment{Transaction: "uid-1", Amount: 1817},
+        Items:       []models.Item{{ChrtID: 9934930, Name: "Mascaras", NmID: 2389212}},
+    }
+
+    mockService := &MockOrderService{}
+    mockService.On("GetByUID", mock.Anything, "uid-1").Return(order, nil)
+    mockService.On("GetByUID", mock.Anything, "missing").Return(models.Order{}, models.OrderNotFoundError{OrderUID: "missing"})
+    mockService.On("GetByUID", mock.Anything, "db-down").Return(models.Order{}, models.DatabaseError{Operation: "get", Err: assert.AnError})
+
+    client, _ := startServer(t, mockService)
+    ctx := context.Background()
+
+    resp, err := client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderUid: "uid-1"})
+    require.NoError(t, err)
+    assert.Equal(t, "uid-1", resp.GetOrder().GetOrderUid())
+    assert.Equal(t, int64(1817), resp.GetOrder().GetPayment().GetAmount())
+    assert.Equal(t, int64(2389212), resp.GetOrder().GetItems()[0].GetNmId())
+    assert.True(t, resp.GetOrder().GetDateCreated().AsTime().Equal(created))
+
+    for uid, code := range map[string]codes.Code{
+        "":        codes.InvalidArgument,
+        "missing": codes.NotFound,
+        "db-down": codes.Unavailable,
+    } {
+        _, err := client.GetOrder(ctx, &ordersv1.GetOrderRequest{OrderUid: uid})
+        assert.Equal(t, code, status.Code(err), uid)
+    }
+}
+
+func TestGetOrder_HidesStorageErrors(t *testing.T) {
+    dbErr := models.DatabaseError{Operation: "repository.postgres.GetByUID", Err: errors.New("dial tcp 10.0.0.5:5432: connection refused")}
+    mockService := &MockOrderService{}
+    mockService.On("GetByUID", mock.Anything, "db-down").Return(models.Order{}, dbErr)
+    mockService.On("GetByUID", mock.Anything, "broken").Return(models.Order{}, errors.New("unexpected nil row at 10.0.0.5"))
+
+    client, _ := startServer(t, mockService)
+
+    for uid, want := range map[string]string{
+        "db-down": "storage is temporarily unavailable",
+        "broken":  "internal server error",
+    } {
+        _, err := client.GetOrder(context.Background(), &ordersv1.GetOrderRequest{OrderUid: uid})
+        msg := status.Convert(err).Message()
+        assert.Equal(t, want, msg, uid)
+        assert.NotContains(t, msg, "10.0.0.5", uid)
+        assert.NotContains(t, msg, dbErr.Operation, uid)
+    }
+}
+
+func TestGetLatestOrders(t *testing.T) {
+    mockService := &MockOrderService{}
+    mockService.On("GetLatest", mock.Anything, defaultLatestLimit).
+        Return([]models.Order{{OrderUID: "uid-2"}, {OrderUID: "uid-1"}}, nil)
+
+    client, _ := startServer(t, mockService)
+    ctx := context.Background()
+
+    resp, err := client.GetLatestOrders(ctx, &ordersv1.GetLatestOrdersRequest{})
+    require.NoError(t, err)
+    require.Len(t, resp.GetOrders(), 2)
+    assert.Equal(t, "uid-2", resp.GetOrders()[0].GetOrderUid())
+
+    _, err = client.GetLatestOrders(ctx, &ordersv1.GetLatestOrdersRequest{Limit: maxLatestLimit + 1})
+    assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWatchOrders(t *testing.T) {
+    events := make(chan service.OrderEvent, 1)
+    mockService := &MockOrderService{}
+    missed := []service.OrderEvent{{ID: 3, Order: models.Order{OrderUID: "uid-3"}}}
+    opts := service.SubscribeOptions{AfterID: 2, CustomerID: "customer-1"}
+    mockService.On("Subscribe", mock.Anything, opts).Return(missed, (<-chan service.OrderEvent)(events))
+
+    client, server := startServer(t, mockService)
+
+    stream, err := client.WatchOrders(context.Background(), &ordersv1.WatchOrdersRequest{CustomerId: "customer-1", AfterEventId: 2})
+    require.NoError(t, err)
+
+    // Сначала заказы из истории, затем новые
+    resp, err := stream.Recv()
+    require.NoError(t, err)
+    assert.Equal(t, "uid-3", resp.GetOrder().GetOrderUid())
+    assert.Equal(t, uint64(3), resp.GetEventId())
+
+    events <- service.OrderEvent{ID: 4, Order: models.Order{OrderUID: "uid-4"}}
+    resp, err = stream.Recv()
+    require.NoError(t, err)
+    assert.Equal(t, "uid-4", resp.GetOrder().GetOrderUid())
+    assert.Equal(t, uint64(4), resp.GetEventId())
+
+    // Остановка сервера завершает открытый стрим, а не ждёт клиента
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    require.NoError(t, server.Shutdown(ctx))
+
+    _, err = stream.Recv()
+    assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+    "bufio"
+    "bytes"
+    "errors"
+    "io"
+    "net/http"
+
+    "L0/internal/models"
+)
+
+const (
+    maxOrderBodySize = 1 << 20  // один заказ
+    maxBatchBodySize = 32 << 20 // NDJSON целиком
+    maxBatchOrders   = 1000
+)
+
+// BatchOrderResult - результат одной строки NDJSON. Status - HTTP статус,
+// который получил бы этот заказ в POST /orders
+type BatchOrderResult struct {
+    Line     int              `json:"line"`
+    OrderUID string           `json:"order_uid,omitempty"`
+    Status   int              `json:"status"`
+    Error    *models.APIError `json:"error,omitempty"`
+}
+
+type BatchCreateResponse struct {
+    Created int                `json:"created"`
+    Failed  int                `json:"failed"`
+    Results []BatchOrderResult `json:"results"`
+}
+
+// CreateOrder godoc
+// @Summary Create order
+// @Description Принимает заказ в том же формате, что и сообщения Kafka (см. testdata/valid-order-template.json), и сохраняет его
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param order body models.Order true "Заказ"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders [post]
+func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+    data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxOrderBodySize))
+    if err != nil {
+        writeBodyError(w, r, err)
+        return
+    }
+
+    order, err := decodeOrder(data)
+    if err != nil {
+        writeError(w, r, err)
+        return
+    }
+
+    if err := h.service.Create(r.Context(), order); err != nil {
+        writeError(w, r, err)
+        return
+    }
+
+    w.Header().Set("Location", "/order/"+order.OrderUID)
+    writeJSON(w, order, http.StatusCreated)
+}
+
+// CreateOrdersBatch godoc
+// @Summary Create orders from NDJSON
+// @Description Принимает до 1000 заказов, по одному JSON на строку (application/x-ndjson). Невалидные строки не мешают сохранению остальных; результат возвращается по каждой строке
+// @Tags orders
+// @Accept application/x-ndjson
+// @Produce json
+// @Param orders body string true "Заказы в формате NDJSON"
+// @Success 200 {object} BatchCreateResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 413 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders/batch [post]
+func (h *OrderHandler) CreateOrdersBatch(w http.ResponseWriter, r *http.Request) {
+    scanner := bufio.NewScanner(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+    scanner.Buffer(make([]byte, 0, 64*1024), maxOrderBodySize)
+
+    var (
+        results []BatchOrderResult
+        orders  []models.Order
+        indexes []int // позиция заказа из orders в results
+    )
+
+    line := 0
+    for scanner.Scan() {
+        line++
+        data := bytes.TrimSpace(scanner.Bytes())
+        if len(data) == 0 {
+            continue
+        }
+        if len(results) == maxBatchOrders {
+            writeErrorMessage(w, r, http.StatusRequestEntityTooLarge, "too many orders in batch, the limit is 1000")
+            return
+        }
+
+        order, err := decodeOrder(data)
+        result := BatchOrderResult{Line: line, OrderUID: order.OrderUID}
+        if err != nil {
+            result.setError(err)
+        } else {
+            orders = append(orders, order)
+            indexes = append(indexes, len(results))
+        }
+        results = append(results, result)
+    }
+    if err := scanner.Err(); err != nil {
+        writeBodyError(w, r, err)
+        return
+    }
+    if len(results) == 0 {
+        writeErrorMessage(w, r, http.StatusBadRequest, "request body contains no orders")
+        return
+    }
+
+    if len(orders) > 0 {
+        errs, err := h.service.CreateBatch(r.Context(), orders)
+        if err != nil {
+            writeError(w, r, err)
+            return
+        }
+
+        for i, err := range errs {
+            result := &results[indexes[i]]
+            result.Status = http.StatusCreated
+            if err != nil {
+                result.setError(err)
+            }
+        }
+    }
+
+    resp := BatchCreateResponse{Results: results}
+    for _, result := range results {
+        if result.Status == http.StatusCreated {
+            resp.Created++
+        } else {
+            resp.Failed++
+        }
+    }
+
+    writeJSON(w, resp, http.StatusOK)
+}
+
+func (res *BatchOrderResult) setError(err error) {
+    status, apiErr := apiError(err)
+    res.Status = status
+    res.Error = &apiErr
+}
+
+// decodeOrder разбирает и проверяет заказ. Ошибка синтаксиса JSON
+// становится ошибкой запроса, ошибки валидации возвращаются как есть
+func decodeOrder(data []byte) (models.Order, error) {
+    order, err := models.DecodeOrder(data)
+    var validationErr models.ValidationError
+    if err != nil && !errors.As(err, &validationErr) {
+        return order, requestError{"malformed order JSON: " + err.Error()}
+    }
+    return order, err
+}
+
+// writeBodyError отвечает на ошибку чтения тела запроса
+func writeBodyError(w http.ResponseWriter, r *http.Request, err error) {
+    var maxBytesErr *http.MaxBytesError
+    if errors.As(err, &maxBytesErr) || errors.Is(err, bufio.ErrTooLong) {
+        writeErrorMessage(w, r, http.StatusRequestEntityTooLarge, "request body is too large")
+        return
+    }
+    writeErrorMessage(w, r, http.StatusBadRequest, "failed to read request body")
+}
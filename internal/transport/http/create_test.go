@@ -0,0 +1,159 @@
+package http
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+
+    "L0/internal/models"
+
+    "github.com/go-chi/chi/v5"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+)
+
+func readTestData(t *testing.T, name string) []byte {
+    t.Helper()
+    data, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", name))
+    require.NoError(t, err)
+    return data
+}
+
+func newCreateRouter(srv *MockOrderService) *chi.Mux {
+    handler := &OrderHandler{service: srv}
+    r := chi.NewRouter()
+    r.Post("/orders", handler.CreateOrder)
+    r.Post("/orders/batch", handler.CreateOrdersBatch)
+    return r
+}
+
+func TestCreateOrder(t *testing.T) {
+    tests := []struct {
+        name       string
+        body       []byte
+        serviceErr error
+        wantStatus int
+    }{
+        {name: "created", body: readTestData(t, "valid-order-template.json"), wantStatus: http.StatusCreated},
+        {name: "duplicate", body: readTestData(t, "valid-order-template.json"), serviceErr: models.OrderAlreadyExistsError{OrderUID: "b563feb7b2b84b6test"}, wantStatus: http.StatusConflict},
+        {name: "storage unavailable", body: readTestData(t, "valid-order-template.json"), serviceErr: models.DatabaseError{Operation: "create", Err: assert.AnError}, wantStatus: http.StatusServiceUnavailable},
+        {name: "invalid order", body: readTestData(t, "error-negative-amount.json"), wantStatus: http.StatusUnprocessableEntity},
+        {name: "wrong type", body: readTestData(t, "error-wrong-type.json"), wantStatus: http.StatusBadRequest},
+        {name: "invalid syntax", body: readTestData(t, "error-invalid-syntax.json"), wantStatus: http.StatusBadRequest},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            mockService := &MockOrderService{}
+            mockService.On("Create", mock.Anything, mock.Anything).Return(tt.serviceErr)
+
+            req := httptest.NewRequest("POST", "/orders", bytes.NewReader(tt.body))
+            w := httptest.NewRecorder()
+            newCreateRouter(mockService).ServeHTTP(w, req)
+
+            assert.Equal(t, tt.wantStatus, w.Code)
+            if tt.wantStatus == http.StatusUnprocessableEntity || tt.wantStatus == http.StatusBadRequest {
+                mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+            }
+        })
+    }
+}
+
+func TestCreateOrder_ResponseBodies(t *testing.T) {
+    mockService := &MockOrderService{}
+    mockService.On("Create", mock.Anything, mock.Anything).Return(nil)
+    r := newCreateRouter(mockService)
+
+    req := httptest.NewRequest("POST", "/orders", bytes.NewReader(readTestData(t, "valid-order-template.json")))
+    w := httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+
+    require.Equal(t, http.StatusCreated, w.Code)
+    assert.Equal(t, "/order/b563feb7b2b84b6test", w.Header().Get("Location"))
+    var order models.Order
+    require.NoError(t, json.NewDecoder(w.Body).Decode(&order))
+    assert.Equal(t, "b563feb7b2b84b6test", order.OrderUID)
+
+    req = httptest.NewRequest("POST", "/orders", bytes.NewReader(readTestData(t, "error-negative-amount.json")))
+    w = httptest.NewRecorder()
+    r.ServeHTTP(w, req)
+
+    require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+    var resp models.ErrorResponse
+    require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+    assert.Equal(t, models.ErrCodeValidationFailed, resp.Error.Code)
+    assert.Contains(t, resp.Error.Details, models.InvalidOrderDataError{Field: "payment.amount", Message: "must not be negative"})
+}
+
+func TestCreateOrdersBatch(t *testing.T) {
+    valid := readTestData(t, "valid-order-template.json")
+
+    var order models.Order
+    require.NoError(t, json.Unmarshal(valid, &order))
+    order.OrderUID = "second-uid"
+    order.Payment.Transaction = "second-uid"
+    second, err := json.Marshal(order)
+    require.NoError(t, err)
+
+    compact := func(data []byte) string {
+        var buf bytes.Buffer
+        require.NoError(t, json.Compact(&buf, data))
+        return buf.String()
+    }
+
+    // Строки: валидный заказ, невалидный заказ, битый JSON, пустая строка, дубликат
+    lines := []string{
+        compact(valid),
+        compact(readTestData(t, "error-empty-items.json")),
+        `{"invalid": json}`,
+        "",
+        compact(second),
+    }
+    body := bytes.NewBufferString(strings.Join(lines, "\n"))
+
+    mockService := &MockOrderService{}
+    mockService.On("CreateBatch", mock.Anything, mock.MatchedBy(func(orders []models.Order) bool {
+        return len(orders) == 2 && orders[1].OrderUID == "second-uid"
+    })).Return([]error{nil, models.OrderAlreadyExistsError{OrderUID: "second-uid"}}, nil)
+
+    req := httptest.NewRequest("POST", "/orders/batch", body)
+    req.Header.Set("Content-Type", "application/x-ndjson")
+    w := httptest.NewRecorder()
+    newCreateRouter(mockService).ServeHTTP(w, req)
+
+    require.Equal(t, http.StatusOK, w.Code)
+    var resp BatchCreateResponse
+    require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+    assert.Equal(t, 1, resp.Created)
+    assert.Equal(t, 3, resp.Failed)
+    require.Len(t, resp.Results, 4)
+    assert.Equal(t, BatchOrderResult{Line: 1, OrderUID: "b563feb7b2b84b6test", Status: http.StatusCreated}, resp.Results[0])
+    assert.Equal(t, http.StatusUnprocessableEntity, resp.Results[1].Status)
+    require.NotNil(t, resp.Results[1].Error)
+    assert.Equal(t, models.ErrCodeValidationFailed, resp.Results[1].Error.Code)
+    assert.NotEmpty(t, resp.Results[1].Error.Details)
+    assert.Equal(t, http.StatusBadRequest, resp.Results[2].Status)
+    assert.Equal(t, models.ErrCodeInvalidRequest, resp.Results[2].Error.Code)
+    assert.Equal(t, 5, resp.Results[3].Line)
+    assert.Equal(t, http.StatusConflict, resp.Results[3].Status)
+    assert.Equal(t, models.ErrCodeAlreadyExists, resp.Results[3].Error.Code)
+    mockService.AssertExpectations(t)
+}
+
+func TestCreateOrdersBatch_Empty(t *testing.T) {
+    mockService := &MockOrderService{}
+
+    req := httptest.NewRequest("POST", "/orders/batch", bytes.NewReader([]byte("\n\n")))
+    w := httptest.NewRecorder()
+    newCreateRouter(mockService).ServeHTTP(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
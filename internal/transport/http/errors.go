@@ -0,0 +1,142 @@
+package http
+
+import (
+    "errors"
+    "log/slog"
+    "net/http"
+
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+
+    "github.com/go-chi/chi/v5/middleware"
+)
+
+// requestError - ошибка во входных данных запроса. Текст написан для
+// пользователя, поэтому его можно показывать как есть
+type requestError struct {
+    message string
+}
+
+func (e requestError) Error() string {
+    return e.message
+}
+
+// errorStatus сопоставляет ошибку сервиса с HTTP статусом
+func errorStatus(err error) int {
+    var notFoundErr models.OrderNotFoundError
+    var existsErr models.OrderAlreadyExistsError
+    var dbErr models.DatabaseError
+    var reqErr requestError
+    var validationErr models.ValidationError
+
+    switch {
+    case errors.As(err, &reqErr), errors.Is(err, models.ErrInvalidCursor), errors.Is(err, models.ErrInvalidLimit):
+        return http.StatusBadRequest
+    case errors.As(err, &validationErr):
+        return http.StatusUnprocessableEntity
+    case errors.As(err, &notFoundErr):
+        return http.StatusNotFound
+    case errors.As(err, &existsErr):
+        return http.StatusConflict
+    case errors.As(err, &dbErr):
+        return http.StatusServiceUnavailable
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+var errorCodes = map[int]string{
+    http.StatusBadRequest:            models.ErrCodeInvalidRequest,
+    http.StatusNotFound:              models.ErrCodeNotFound,
+    http.StatusMethodNotAllowed:      models.ErrCodeMethodNotAllowed,
+    http.StatusConflict:              models.ErrCodeAlreadyExists,
+    http.StatusRequestEntityTooLarge: models.ErrCodePayloadTooLarge,
+    http.StatusUnprocessableEntity:   models.ErrCodeValidationFailed,
+    http.StatusTooManyRequests:       models.ErrCodeRateLimited,
+    http.StatusServiceUnavailable:    models.ErrCodeUnavailable,
+}
+
+// errorCode возвращает код ошибки JSON API для HTTP статуса
+func errorCode(status int) string {
+    if code, ok := errorCodes[status]; ok {
+        return code
+    }
+    return models.ErrCodeInternal
+}
+
+// apiError переводит ошибку сервиса в статус и ошибку API.
+// Текст внутренних ошибок клиенту не отдаётся, он остаётся в логах
+func apiError(err error) (int, models.APIError) {
+    status := errorStatus(err)
+    apiErr := models.APIError{Code: errorCode(status), Message: err.Error()}
+
+    var validationErr models.ValidationError
+    switch {
+    case errors.As(err, &validationErr):
+        apiErr.Message = "order validation failed"
+        apiErr.Details = validationErr.Errors
+    case status == http.StatusServiceUnavailable:
+        apiErr.Message = "storage is temporarily unavailable"
+    case status >= http.StatusInternalServerError:
+        apiErr.Message = "internal server error"
+    }
+    return status, apiErr
+}
+
+// writeError отвечает ошибкой сервиса в общем формате
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+    status, apiErr := apiError(err)
+    if status >= http.StatusInternalServerError {
+        slog.ErrorContext(r.Context(), "request failed", "error", err, "method", r.Method, "path", r.URL.Path, "request_id", middleware.GetReqID(r.Context()))
+    }
+    mw.WriteError(w, r, status, apiErr.Code, apiErr.Message, apiErr.Details...)
+}
+
+// writeErrorMessage отвечает ошибкой запроса с готовым текстом
+func writeErrorMessage(w http.ResponseWriter, r *http.Request, status int, message string) {
+    mw.WriteError(w, r, status, errorCode(status), message)
+}
+
+// errorView - блок ошибки на странице веб-интерфейса
+type errorView struct {
+    Status  int
+    Title   string
+    Message string
+}
+
+var errorViews = map[int]errorView{
+    http.StatusBadRequest: {
+        Title:   "Некорректный запрос",
+        Message: "Проверьте параметры поиска и попробуйте ещё раз.",
+    },
+    http.StatusNotFound: {
+        Title:   "Заказ не найден",
+        Message: "Заказа с таким UID нет. Проверьте, что он скопирован целиком.",
+    },
+    http.StatusServiceUnavailable: {
+        Title:   "Сервис временно недоступен",
+        Message: "Не удалось получить данные о заказах. Обновите страницу через минуту.",
+    },
+    http.StatusInternalServerError: {
+        Title:   "Что-то пошло не так",
+        Message: "Внутренняя ошибка сервиса. Мы уже знаем о ней.",
+    },
+}
+
+// newErrorView подбирает статус и текст ошибки по тому же сопоставлению, что и
+// JSON API. Текст исходной ошибки показывается только для ошибок ввода
+func newErrorView(err error) errorView {
+    status := errorStatus(err)
+    view, ok := errorViews[status]
+    if !ok {
+        status = http.StatusInternalServerError
+        view = errorViews[status]
+    }
+    view.Status = status
+
+    var reqErr requestError
+    if errors.As(err, &reqErr) {
+        view.Message = reqErr.message
+    }
+    return view
+}
@@ -2,12 +2,17 @@ package http
 
 import (
     "encoding/json"
-    "errors"
+    "fmt"
     "html/template"
     "log/slog"
     "net/http"
+    "path/filepath"
+    "strconv"
+    "sync"
+    "time"
 
     "L0/internal/models"
+    "L0/internal/pii"
     "L0/internal/service"
 
     "github.com/go-chi/chi/v5"
@@ -16,10 +21,17 @@ import (
 type OrderHandler struct {
     service service.OrderService
     tmpl    *template.Template
+    masker  *pii.Masker
+
+    // closing закрывается при остановке сервера и завершает открытые SSE потоки
+    closing   chan struct{}
+    closeOnce sync.Once
 }
 
-func NewOrderHandler(srv service.OrderService, templatePath string) (*OrderHandler, error) {
-    tmpl, err := template.ParseFiles(templatePath)
+// NewOrderHandler загружает все *.html шаблоны веб-интерфейса из templateDir.
+// masker скрывает персональные данные от ролей ниже PII_UNMASKED_ROLE
+func NewOrderHandler(srv service.OrderService, templateDir string, masker *pii.Masker) (*OrderHandler, error) {
+    tmpl, err := template.New("").Funcs(templateFuncs).ParseGlob(filepath.Join(templateDir, "*.html"))
     if err != nil {
         return nil, err
     }
@@ -27,51 +39,11 @@ func NewOrderHandler(srv service.OrderService, templatePath string) (*OrderHandl
     return &OrderHandler{
         service: srv,
         tmpl:    tmpl,
+        masker:  masker,
+        closing: make(chan struct{}),
     }, nil
 }
 
-type ErrorResponse struct {
-    Error string `json:"error"`
-}
-
-// GetOrderPage godoc
-// @Summary Поиск заказа по UID
-// @Description Возвращает HTML-страницу с деталями заказа
-// @Tags orders
-// @Accept  html
-// @Produce html
-// @Param order_uid query string true "UID заказа"
-// @Success 200 {string} string "HTML страница"
-// @Failure 404 {string} string "Заказ не найден"
-// @Router / [get]
-func (h *OrderHandler) GetOrderPage(w http.ResponseWriter, r *http.Request) {
-    uidQuery := r.URL.Query().Get("order_uid")
-
-    pageData := struct {
-        UIDQuery string
-        Order    *models.Order
-        Error    string
-    }{
-        UIDQuery: uidQuery,
-    }
-
-    if uidQuery != "" {
-        order, err := h.service.GetByUID(r.Context(), uidQuery)
-        if err != nil {
-            pageData.Error = err.Error()
-        } else {
-            pageData.Order = &order
-            pageData.Error = ""
-        }
-    }
-
-    err := h.tmpl.Execute(w, pageData)
-    if err != nil {
-        slog.Error("failed to execute template", "error", err)
-        http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-    }
-}
-
 // GetOrderByPath godoc
 // @Summary Get order by UID (path parameter)
 // @Description Get order information by order UID from URL path
@@ -80,29 +52,127 @@ func (h *OrderHandler) GetOrderPage(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param order_uid path string true "Order UID"
 // @Success 200 {object} models.Order
-// @Failure 400 {object} ErrorResponse
-// @Failure 404 {object} ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security ApiKeyAuth
 // @Router /order/{order_uid} [get]
 func (h *OrderHandler) GetOrderByPath(w http.ResponseWriter, r *http.Request) {
     orderUID := chi.URLParam(r, "order_uid")
     
     if orderUID == "" {
-        writeJSONError(w, "order_uid is required", http.StatusBadRequest)
+        writeErrorMessage(w, r, http.StatusBadRequest, "order_uid is required")
         return
     }
 
     order, err := h.service.GetByUID(r.Context(), orderUID)
     if err != nil {
-        var notFoundErr models.OrderNotFoundError
-        if errors.As(err, &notFoundErr) {
-            writeJSONError(w, err.Error(), http.StatusNotFound)
-            return
-        }
-        writeJSONError(w, err.Error(), http.StatusInternalServerError)
+        writeError(w, r, err)
         return
     }
 
-    writeJSON(w, order, http.StatusOK)
+    writeJSON(w, h.orderView(r)(order), http.StatusOK)
+}
+
+const (
+    defaultListLimit = 20
+    maxListLimit     = 100
+)
+
+type OrderListResponse struct {
+    Orders     []models.Order `json:"orders"`
+    NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// ListOrders godoc
+// @Summary List orders
+// @Description Постраничный список заказов, отсортированный по дате создания (сначала новые). Для следующей страницы передайте next_cursor из ответа
+// @Tags orders
+// @Produce json
+// @Param customer_id query string false "ID клиента"
+// @Param track_number query string false "Трек-номер"
+// @Param delivery_service query string false "Служба доставки"
+// @Param locale query string false "Локаль"
+// @Param currency query string false "Валюта оплаты (payment.currency)"
+// @Param date_from query string false "date_created от (RFC 3339, включительно)"
+// @Param date_to query string false "date_created до (RFC 3339, не включительно)"
+// @Param limit query int false "Размер страницы (1-100, по умолчанию 20)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {object} OrderListResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders [get]
+func (h *OrderHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+    filter, err := parseOrderFilter(r)
+    if err != nil {
+        writeErrorMessage(w, r, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    page, err := h.service.List(r.Context(), filter)
+    if err != nil {
+        writeError(w, r, err)
+        return
+    }
+
+    resp := OrderListResponse{Orders: viewOrders(page.Orders, h.orderView(r))}
+    if page.NextCursor != nil {
+        resp.NextCursor = page.NextCursor.Encode()
+    }
+
+    writeJSON(w, resp, http.StatusOK)
+}
+
+func parseOrderFilter(r *http.Request) (models.OrderFilter, error) {
+    q := r.URL.Query()
+
+    filter := models.OrderFilter{
+        CustomerID:      q.Get("customer_id"),
+        TrackNumber:     q.Get("track_number"),
+        DeliveryService: q.Get("delivery_service"),
+        Locale:          q.Get("locale"),
+        Currency:        q.Get("currency"),
+        Limit:           defaultListLimit,
+    }
+
+    if v := q.Get("limit"); v != "" {
+        limit, err := strconv.Atoi(v)
+        if err != nil || limit < 1 || limit > maxListLimit {
+            return models.OrderFilter{}, fmt.Errorf("limit must be an integer between 1 and %d", maxListLimit)
+        }
+        filter.Limit = limit
+    }
+
+    for _, p := range []struct {
+        name string
+        dst  *time.Time
+    }{
+        {"date_from", &filter.CreatedFrom},
+        {"date_to", &filter.CreatedTo},
+    } {
+        v := q.Get(p.name)
+        if v == "" {
+            continue
+        }
+        t, err := time.Parse(time.RFC3339, v)
+        if err != nil {
+            return models.OrderFilter{}, fmt.Errorf("%s must be an RFC 3339 timestamp", p.name)
+        }
+        *p.dst = t
+    }
+
+    if v := q.Get("cursor"); v != "" {
+        cursor, err := models.DecodeOrderCursor(v)
+        if err != nil {
+            return models.OrderFilter{}, err
+        }
+        filter.Cursor = &cursor
+    }
+
+    return filter, nil
 }
 
 func writeJSON(w http.ResponseWriter, data interface{}, status int) {
@@ -112,7 +182,3 @@ func writeJSON(w http.ResponseWriter, data interface{}, status int) {
         slog.Error("failed to encode JSON", "error", err)
     }
 }
-
-func writeJSONError(w http.ResponseWriter, message string, status int) {
-    writeJSON(w, ErrorResponse{Error: message}, status)
-}
\ No newline at end of file
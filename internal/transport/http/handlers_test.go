@@ -2,12 +2,15 @@ package http
 
 import (
     "context"
+    "encoding/json"
     "errors"
     "net/http"
     "net/http/httptest"
     "testing"
+    "time"
 
     "L0/internal/models"
+    "L0/internal/service"
 
     "github.com/go-chi/chi/v5"
     "github.com/stretchr/testify/assert"
@@ -19,6 +22,12 @@ type MockOrderService struct {
     mock.Mock
 }
 
+func (m *MockOrderService) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    args := m.Called(ctx, orders)
+    results, _ := args.Get(0).([]error)
+    return results, args.Error(1)
+}
+
 func (m *MockOrderService) GetByUID(ctx context.Context, uid string) (models.Order, error) {
     args := m.Called(ctx, uid)
     return args.Get(0).(models.Order), args.Error(1)
@@ -34,6 +43,27 @@ func (m *MockOrderService) GetLatest(ctx context.Context, limit int) ([]models.O
     return args.Get(0).([]models.Order), args.Error(1)
 }
 
+func (m *MockOrderService) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    args := m.Called(ctx, filter)
+    return args.Get(0).(models.OrderPage), args.Error(1)
+}
+
+func (m *MockOrderService) WarmUpCache(ctx context.Context) error {
+    args := m.Called(ctx)
+    return args.Error(0)
+}
+
+func (m *MockOrderService) SetCacheTTL(ttl time.Duration) {
+    m.Called(ttl)
+}
+
+func (m *MockOrderService) Subscribe(ctx context.Context, opts service.SubscribeOptions) ([]service.OrderEvent, <-chan service.OrderEvent) {
+    args := m.Called(ctx, opts)
+    missed, _ := args.Get(0).([]service.OrderEvent)
+    ch, _ := args.Get(1).(<-chan service.OrderEvent)
+    return missed, ch
+}
+
 func TestGetOrderByPath_Success(t *testing.T) {
     mockService := &MockOrderService{}
 
@@ -97,4 +127,88 @@ func TestGetOrderByPath_ServiceError(t *testing.T) {
 
     mockService.AssertExpectations(t)
     assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestListOrders_FiltersAndCursor(t *testing.T) {
+    mockService := &MockOrderService{}
+
+    created := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+    page := models.OrderPage{
+        Orders:     []models.Order{{OrderUID: "uid-1", DateCreated: created}},
+        NextCursor: &models.OrderCursor{DateCreated: created, OrderUID: "uid-1"},
+    }
+
+    mockService.On("List", mock.Anything, mock.MatchedBy(func(f models.OrderFilter) bool {
+        return f.CustomerID == "test" &&
+            f.Currency == "USD" &&
+            f.Limit == 1 &&
+            f.CreatedFrom.Equal(time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)) &&
+            f.Cursor == nil
+    })).Return(page, nil).Once()
+
+    handler := &OrderHandler{service: mockService}
+
+    r := chi.NewRouter()
+    r.Get("/orders", handler.ListOrders)
+
+    req := httptest.NewRequest("GET", "/orders?customer_id=test&currency=USD&limit=1&date_from=2021-11-01T00:00:00Z", nil)
+    w := httptest.NewRecorder()
+
+    r.ServeHTTP(w, req)
+
+    mockService.AssertExpectations(t)
+    assert.Equal(t, http.StatusOK, w.Code)
+
+    var resp OrderListResponse
+    assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+    assert.Len(t, resp.Orders, 1)
+    assert.NotEmpty(t, resp.NextCursor)
+
+    // Курсор из ответа принимается следующим запросом
+    cursor, err := models.DecodeOrderCursor(resp.NextCursor)
+    assert.NoError(t, err)
+    assert.Equal(t, "uid-1", cursor.OrderUID)
+    assert.True(t, cursor.DateCreated.Equal(created))
+}
+
+func TestListOrders_BadRequest(t *testing.T) {
+    handler := &OrderHandler{service: &MockOrderService{}}
+
+    r := chi.NewRouter()
+    r.Get("/orders", handler.ListOrders)
+
+    for _, query := range []string{"limit=0", "limit=1000", "date_to=yesterday", "cursor=not-a-cursor"} {
+        req := httptest.NewRequest("GET", "/orders?"+query, nil)
+        w := httptest.NewRecorder()
+
+        r.ServeHTTP(w, req)
+
+        assert.Equal(t, http.StatusBadRequest, w.Code, query)
+    }
+}
+
+func TestGetOrderByPath_DatabaseUnavailable(t *testing.T) {
+    mockService := &MockOrderService{}
+
+    dbErr := models.DatabaseError{Operation: "repository.postgres.GetByUID", Err: errors.New("connection refused")}
+    mockService.On("GetByUID", mock.Anything, "test-uid").Return(models.Order{}, dbErr)
+
+    handler := &OrderHandler{service: mockService}
+
+    r := chi.NewRouter()
+    r.Get("/order/{order_uid}", handler.GetOrderByPath)
+
+    req := httptest.NewRequest("GET", "/order/test-uid", nil)
+    w := httptest.NewRecorder()
+
+    r.ServeHTTP(w, req)
+
+    mockService.AssertExpectations(t)
+    assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+    var resp models.ErrorResponse
+    assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+    assert.Equal(t, models.ErrCodeUnavailable, resp.Error.Code)
+    // Подробности ошибки БД остаются в логах
+    assert.NotContains(t, resp.Error.Message, "connection refused")
 }
\ No newline at end of file
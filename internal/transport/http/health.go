@@ -0,0 +1,139 @@
+package http
+
+import (
+    "context"
+    "log/slog"
+    "net/http"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Состояния сервиса для readiness-проверки
+const (
+    stateStarting int32 = iota
+    stateReady
+    stateStopping
+)
+
+var stateNames = map[int32]string{
+    stateStarting: "starting",
+    stateReady:    "ready",
+    stateStopping: "stopping",
+}
+
+// HealthCheck проверяет одну зависимость сервиса.
+// Details попадают в ответ /readyz как есть, а текст ошибки - только в лог:
+// /readyz доступен без ключа, а в ошибках бывают адреса хостов и пути к файлам.
+type HealthCheck struct {
+    Name  string
+    Check func(ctx context.Context) (details map[string]any, err error)
+}
+
+type HealthHandler struct {
+    checks  []HealthCheck
+    timeout time.Duration
+    state   atomic.Int32
+}
+
+func NewHealthHandler(timeout time.Duration, checks ...HealthCheck) *HealthHandler {
+    return &HealthHandler{
+        checks:  checks,
+        timeout: timeout,
+    }
+}
+
+// SetReady вызывается после завершения стартовых задач
+func (h *HealthHandler) SetReady() {
+    h.state.Store(stateReady)
+}
+
+// SetStopping вызывается в начале graceful shutdown
+func (h *HealthHandler) SetStopping() {
+    h.state.Store(stateStopping)
+}
+
+type DependencyStatus struct {
+    Status  string         `json:"status"`
+    Details map[string]any `json:"details,omitempty"`
+}
+
+type ReadinessResponse struct {
+    Status       string                      `json:"status"`
+    State        string                      `json:"state"`
+    Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Liveness godoc
+// @Summary Liveness probe
+// @Description Процесс запущен и обрабатывает запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+}
+
+// Readiness godoc
+// @Summary Readiness probe
+// @Description Проверяет доступность зависимостей. Возвращает 503 во время старта, остановки или при недоступной зависимости
+// @Tags health
+// @Produce json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+    ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+    defer cancel()
+
+    state := h.state.Load()
+    resp := ReadinessResponse{
+        Status:       "ok",
+        State:        stateNames[state],
+        Dependencies: h.runChecks(ctx),
+    }
+
+    if state != stateReady {
+        resp.Status = "unavailable"
+    }
+    for _, dep := range resp.Dependencies {
+        if dep.Status != "ok" {
+            resp.Status = "unavailable"
+        }
+    }
+
+    status := http.StatusOK
+    if resp.Status != "ok" {
+        status = http.StatusServiceUnavailable
+    }
+    writeJSON(w, resp, status)
+}
+
+// runChecks выполняет проверки параллельно, чтобы медленная зависимость не задерживала остальные
+func (h *HealthHandler) runChecks(ctx context.Context) map[string]DependencyStatus {
+    result := make(map[string]DependencyStatus, len(h.checks))
+    var mu sync.Mutex
+    var wg sync.WaitGroup
+
+    for _, check := range h.checks {
+        wg.Add(1)
+        go func(check HealthCheck) {
+            defer wg.Done()
+
+            details, err := check.Check(ctx)
+            dep := DependencyStatus{Status: "ok", Details: details}
+            if err != nil {
+                dep.Status = "error"
+                slog.WarnContext(ctx, "Health check failed", "dependency", check.Name, "error", err)
+            }
+
+            mu.Lock()
+            result[check.Name] = dep
+            mu.Unlock()
+        }(check)
+    }
+
+    wg.Wait()
+    return result
+}
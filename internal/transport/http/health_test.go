@@ -0,0 +1,87 @@
+package http
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+func okCheck(name string) HealthCheck {
+    return HealthCheck{Name: name, Check: func(context.Context) (map[string]any, error) {
+        return nil, nil
+    }}
+}
+
+func doReadiness(t *testing.T, h *HealthHandler) (int, ReadinessResponse) {
+    t.Helper()
+
+    code, body := doReadinessRaw(t, h)
+    var resp ReadinessResponse
+    require.NoError(t, json.Unmarshal(body, &resp))
+    return code, resp
+}
+
+func doReadinessRaw(t *testing.T, h *HealthHandler) (int, []byte) {
+    t.Helper()
+
+    w := httptest.NewRecorder()
+    h.Readiness(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+    return w.Code, w.Body.Bytes()
+}
+
+func TestHealth_Liveness(t *testing.T) {
+    h := NewHealthHandler(time.Second)
+
+    w := httptest.NewRecorder()
+    h.Liveness(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+    assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealth_Readiness_States(t *testing.T) {
+    h := NewHealthHandler(time.Second, okCheck("postgres"), okCheck("kafka"))
+
+    code, resp := doReadiness(t, h)
+    assert.Equal(t, http.StatusServiceUnavailable, code)
+    assert.Equal(t, "starting", resp.State)
+
+    h.SetReady()
+    code, resp = doReadiness(t, h)
+    assert.Equal(t, http.StatusOK, code)
+    assert.Equal(t, "ok", resp.Status)
+    assert.Equal(t, "ok", resp.Dependencies["postgres"].Status)
+    assert.Equal(t, "ok", resp.Dependencies["kafka"].Status)
+
+    h.SetStopping()
+    code, resp = doReadiness(t, h)
+    assert.Equal(t, http.StatusServiceUnavailable, code)
+    assert.Equal(t, "stopping", resp.State)
+}
+
+func TestHealth_Readiness_FailedDependency(t *testing.T) {
+    h := NewHealthHandler(time.Second,
+        okCheck("postgres"),
+        HealthCheck{Name: "kafka", Check: func(context.Context) (map[string]any, error) {
+            return map[string]any{"lag": 100}, errors.New("no kafka broker reachable: dial tcp 10.0.0.7:9092: connection refused")
+        }},
+    )
+    h.SetReady()
+
+    code, resp := doReadiness(t, h)
+    assert.Equal(t, http.StatusServiceUnavailable, code)
+    assert.Equal(t, "unavailable", resp.Status)
+    assert.Equal(t, "ok", resp.Dependencies["postgres"].Status)
+    assert.Equal(t, "error", resp.Dependencies["kafka"].Status)
+    assert.EqualValues(t, 100, resp.Dependencies["kafka"].Details["lag"])
+
+    // Текст ошибки остаётся в логе: /readyz открыт без ключа
+    _, body := doReadinessRaw(t, h)
+    assert.NotContains(t, string(body), "10.0.0.7")
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+    "net/http"
+
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+    "L0/internal/pii"
+)
+
+// defaultMasker - правила по умолчанию для обработчика, созданного без NewOrderHandler
+var defaultMasker = mustMasker(pii.NewMasker("", string(models.RoleSupport)))
+
+func mustMasker(m *pii.Masker, err error) *pii.Masker {
+    if err != nil {
+        panic(err)
+    }
+    return m
+}
+
+// orderView возвращает функцию, которая готовит заказ к отдаче вызывающему.
+// Без аутентификации заказ отдаётся как есть, иначе персональные данные
+// скрываются по правилам PII_MASK_RULES для роли ключа
+func (h *OrderHandler) orderView(r *http.Request) func(models.Order) models.Order {
+    key, ok := mw.APIKeyFromContext(r.Context())
+    if !ok {
+        return func(order models.Order) models.Order { return order }
+    }
+
+    masker := h.masker
+    if masker == nil {
+        masker = defaultMasker
+    }
+    return func(order models.Order) models.Order { return masker.Mask(order, key.Role) }
+}
+
+func viewOrders(orders []models.Order, view func(models.Order) models.Order) []models.Order {
+    result := make([]models.Order, len(orders))
+    for i, order := range orders {
+        result[i] = view(order)
+    }
+    return result
+}
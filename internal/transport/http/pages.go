@@ -0,0 +1,256 @@
+package http
+
+import (
+    "bytes"
+    "context"
+    "html/template"
+    "log/slog"
+    "net/http"
+    "net/url"
+    "strconv"
+    "time"
+
+    "L0/internal/models"
+)
+
+const dateLayout = "2006-01-02" // формат <input type="date">
+
+var pageSizes = []int{20, 50, 100}
+
+// maxOrderUIDLength - длина order_uid в схеме БД
+const maxOrderUIDLength = 50
+
+var (
+    errInvalidDate     = requestError{"Дата должна быть в формате ГГГГ-ММ-ДД."}
+    errInvalidPageSize = requestError{"Размер страницы должен быть от 1 до 100."}
+    errInvalidCursor   = requestError{"Ссылка на страницу списка повреждена. Начните с первой страницы."}
+    errOrderUIDTooLong = requestError{"UID заказа не может быть длиннее 50 символов."}
+)
+
+var templateFuncs = template.FuncMap{
+    // unixTime форматирует payment_dt (секунды Unix)
+    "unixTime": func(sec int64) string {
+        if sec == 0 {
+            return ""
+        }
+        return time.Unix(sec, 0).UTC().Format("02.01.2006 15:04:05 MST")
+    },
+}
+
+// orderListFilter - значения формы фильтров в том виде, в каком их ввёл пользователь
+type orderListFilter struct {
+    CustomerID string
+    DateFrom   string
+    DateTo     string
+    Limit      int
+}
+
+type orderListPage struct {
+    Title     string
+    Filter    orderListFilter
+    PageSizes []int
+    Orders    []models.Order
+    Page      int
+    FirstURL  string
+    NextURL   string
+    Error     *errorView
+}
+
+type orderDetailPage struct {
+    Title    string
+    UIDQuery string
+    Order    *models.Order
+    Error    *errorView
+}
+
+// GetOrderPage godoc
+// @Summary Веб-интерфейс заказов
+// @Description Без order_uid возвращает HTML-страницу со списком последних заказов (фильтры по клиенту и дате, постраничный вывод), с order_uid - страницу со всеми полями заказа
+// @Tags orders
+// @Accept  html
+// @Produce html
+// @Param order_uid query string false "UID заказа"
+// @Param customer_id query string false "ID клиента"
+// @Param date_from query string false "Дата создания от (YYYY-MM-DD, включительно)"
+// @Param date_to query string false "Дата создания до (YYYY-MM-DD, включительно)"
+// @Param limit query int false "Размер страницы (20, 50 или 100)"
+// @Param cursor query string false "Курсор следующей страницы"
+// @Success 200 {string} string "HTML страница"
+// @Failure 400 {string} string "Некорректные параметры"
+// @Failure 401 {object} models.ErrorResponse "Нужен API-ключ"
+// @Failure 404 {string} string "Заказ не найден"
+// @Failure 503 {string} string "Хранилище недоступно"
+// @Security ApiKeyAuth
+// @Router / [get]
+func (h *OrderHandler) GetOrderPage(w http.ResponseWriter, r *http.Request) {
+    uidQuery := r.URL.Query().Get("order_uid")
+    if uidQuery == "" {
+        h.renderOrderList(w, r)
+        return
+    }
+
+    pageData := orderDetailPage{
+        Title:    "Заказ " + uidQuery,
+        UIDQuery: uidQuery,
+    }
+
+    order, err := h.getOrder(r, uidQuery)
+    if err != nil {
+        pageData.Error = pageError(r.Context(), err, "failed to get order", "order_uid", uidQuery)
+        pageData.Title = pageData.Error.Title
+        h.renderPage(w, r, "order.html", pageData.Error.Status, pageData)
+        return
+    }
+
+    order = h.orderView(r)(order)
+    pageData.Order = &order
+    h.renderPage(w, r, "order.html", http.StatusOK, pageData)
+}
+
+func (h *OrderHandler) getOrder(r *http.Request, uid string) (models.Order, error) {
+    if len(uid) > maxOrderUIDLength {
+        return models.Order{}, errOrderUIDTooLong
+    }
+    return h.service.GetByUID(r.Context(), uid)
+}
+
+// pageError готовит блок ошибки для страницы; ошибки сервера пишутся в лог,
+// раз пользователь их текст не увидит
+func pageError(ctx context.Context, err error, msg string, args ...any) *errorView {
+    view := newErrorView(err)
+    if view.Status >= http.StatusInternalServerError {
+        slog.ErrorContext(ctx, msg, append(args, "error", err)...)
+    }
+    return &view
+}
+
+// renderOrderList отображает страницу последних заказов
+func (h *OrderHandler) renderOrderList(w http.ResponseWriter, r *http.Request) {
+    q := r.URL.Query()
+
+    pageData := orderListPage{
+        Title: "Последние заказы",
+        Filter: orderListFilter{
+            CustomerID: q.Get("customer_id"),
+            DateFrom:   q.Get("date_from"),
+            DateTo:     q.Get("date_to"),
+            Limit:      pageSizes[0],
+        },
+        PageSizes: pageSizes,
+        Page:      1,
+    }
+
+    filter, err := pageData.Filter.parse(q)
+    if err != nil {
+        pageData.Error = pageError(r.Context(), err, "invalid order list filter")
+        h.renderPage(w, r, "orders.html", pageData.Error.Status, pageData)
+        return
+    }
+    pageData.Filter.Limit = filter.Limit
+
+    if filter.Cursor != nil {
+        if page, err := strconv.Atoi(q.Get("page")); err == nil && page > 1 {
+            pageData.Page = page
+        }
+        pageData.FirstURL = pageData.Filter.url(nil, 0)
+    }
+
+    page, err := h.service.List(r.Context(), filter)
+    if err != nil {
+        pageData.Error = pageError(r.Context(), err, "failed to list orders")
+        h.renderPage(w, r, "orders.html", pageData.Error.Status, pageData)
+        return
+    }
+
+    pageData.Orders = viewOrders(page.Orders, h.orderView(r))
+    if page.NextCursor != nil {
+        pageData.NextURL = pageData.Filter.url(page.NextCursor, pageData.Page+1)
+    }
+
+    h.renderPage(w, r, "orders.html", http.StatusOK, pageData)
+}
+
+// parse превращает параметры формы в фильтр списка. Даты в форме
+// включительные, поэтому date_to сдвигается на сутки вперёд
+func (f orderListFilter) parse(q url.Values) (models.OrderFilter, error) {
+    filter := models.OrderFilter{
+        CustomerID: f.CustomerID,
+        Limit:      f.Limit,
+    }
+
+    if v := q.Get("limit"); v != "" {
+        limit, err := strconv.Atoi(v)
+        if err != nil || limit < 1 || limit > maxListLimit {
+            return models.OrderFilter{}, errInvalidPageSize
+        }
+        filter.Limit = limit
+    }
+
+    if f.DateFrom != "" {
+        from, err := time.Parse(dateLayout, f.DateFrom)
+        if err != nil {
+            return models.OrderFilter{}, errInvalidDate
+        }
+        filter.CreatedFrom = from
+    }
+    if f.DateTo != "" {
+        to, err := time.Parse(dateLayout, f.DateTo)
+        if err != nil {
+            return models.OrderFilter{}, errInvalidDate
+        }
+        filter.CreatedTo = to.AddDate(0, 0, 1)
+    }
+
+    if v := q.Get("cursor"); v != "" {
+        cursor, err := models.DecodeOrderCursor(v)
+        if err != nil {
+            return models.OrderFilter{}, errInvalidCursor
+        }
+        filter.Cursor = &cursor
+    }
+
+    return filter, nil
+}
+
+// url возвращает ссылку на страницу списка с теми же фильтрами
+func (f orderListFilter) url(cursor *models.OrderCursor, page int) string {
+    q := url.Values{}
+    if f.CustomerID != "" {
+        q.Set("customer_id", f.CustomerID)
+    }
+    if f.DateFrom != "" {
+        q.Set("date_from", f.DateFrom)
+    }
+    if f.DateTo != "" {
+        q.Set("date_to", f.DateTo)
+    }
+    if f.Limit != pageSizes[0] {
+        q.Set("limit", strconv.Itoa(f.Limit))
+    }
+    if cursor != nil {
+        q.Set("cursor", cursor.Encode())
+        q.Set("page", strconv.Itoa(page))
+    }
+
+    if len(q) == 0 {
+        return "/"
+    }
+    return "/?" + q.Encode()
+}
+
+// renderPage сначала рендерит шаблон в буфер, чтобы ошибка шаблона
+// не оставила клиенту половину страницы
+func (h *OrderHandler) renderPage(w http.ResponseWriter, r *http.Request, name string, status int, data any) {
+    var buf bytes.Buffer
+    if err := h.tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+        slog.ErrorContext(r.Context(), "failed to execute template", "error", err, "template", name)
+        http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+        return
+    }
+
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    w.WriteHeader(status)
+    if _, err := buf.WriteTo(w); err != nil {
+        slog.WarnContext(r.Context(), "failed to write page", "error", err)
+    }
+}
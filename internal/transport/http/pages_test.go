@@ -0,0 +1,175 @@
+package http
+
+import (
+    "encoding/json"
+    "errors"
+    "html"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "L0/internal/models"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+)
+
+func newPageHandler(t *testing.T, srv *MockOrderService) *OrderHandler {
+    t.Helper()
+    handler, err := NewOrderHandler(srv, filepath.Join("..", "..", "..", "web", "template"), nil)
+    require.NoError(t, err)
+    return handler
+}
+
+func TestGetOrderPage_List(t *testing.T) {
+    mockService := &MockOrderService{}
+    handler := newPageHandler(t, mockService)
+
+    created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+    orders := []models.Order{
+        {OrderUID: "uid-2", CustomerID: "alice", DateCreated: created.Add(time.Hour)},
+        {OrderUID: "uid-1", CustomerID: "alice", DateCreated: created},
+    }
+    // date_to в форме включительная: фильтр до начала следующих суток
+    wantFilter := models.OrderFilter{
+        CustomerID:  "alice",
+        CreatedFrom: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+        CreatedTo:   time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+        Limit:       50,
+    }
+    mockService.On("List", mock.Anything, wantFilter).
+        Return(models.OrderPage{Orders: orders, NextCursor: models.CursorAfter(orders[1])}, nil)
+
+    req := httptest.NewRequest(http.MethodGet, "/?customer_id=alice&date_from=2024-03-01&date_to=2024-03-01&limit=50", nil)
+    w := httptest.NewRecorder()
+    handler.GetOrderPage(w, req)
+
+    mockService.AssertExpectations(t)
+    assert.Equal(t, http.StatusOK, w.Code)
+    assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+
+    body := w.Body.String()
+    assert.Contains(t, body, `href="/?order_uid=uid-2"`)
+    assert.Contains(t, body, `href="/?order_uid=uid-1"`)
+    // Ссылка на следующую страницу сохраняет фильтры
+    assert.Contains(t, body, "cursor="+models.CursorAfter(orders[1]).Encode())
+    assert.Contains(t, body, "customer_id=alice&amp;date_from=2024-03-01&amp;date_to=2024-03-01&amp;limit=50")
+}
+
+func TestGetOrderPage_ListErrors(t *testing.T) {
+    tests := []struct {
+        name       string
+        query      string
+        serviceErr error
+        wantStatus int
+        wantText   string
+    }{
+        {name: "invalid date", query: "?date_from=01.03.2024", wantStatus: http.StatusBadRequest, wantText: errInvalidDate.Error()},
+        {name: "invalid page size", query: "?limit=1000", wantStatus: http.StatusBadRequest, wantText: errInvalidPageSize.Error()},
+        {name: "invalid cursor", query: "?cursor=garbage", wantStatus: http.StatusBadRequest, wantText: errInvalidCursor.Error()},
+        {
+            name:       "storage unavailable",
+            serviceErr: models.DatabaseError{Operation: "list", Err: errors.New("dial tcp 10.0.0.1:5432: connection refused")},
+            wantStatus: http.StatusServiceUnavailable,
+            wantText:   errorViews[http.StatusServiceUnavailable].Title,
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            mockService := &MockOrderService{}
+            handler := newPageHandler(t, mockService)
+            if tt.serviceErr != nil {
+                mockService.On("List", mock.Anything, mock.Anything).Return(models.OrderPage{}, tt.serviceErr)
+            }
+
+            req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+            w := httptest.NewRecorder()
+            handler.GetOrderPage(w, req)
+
+            assert.Equal(t, tt.wantStatus, w.Code)
+            assert.Contains(t, w.Body.String(), html.EscapeString(tt.wantText))
+            assert.NotContains(t, w.Body.String(), "10.0.0.1")
+            // Форма фильтров остаётся на странице
+            assert.Contains(t, w.Body.String(), `name="date_from"`)
+            if tt.serviceErr == nil {
+                mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+            }
+        })
+    }
+}
+
+func TestGetOrderPage_DetailErrors(t *testing.T) {
+    tests := []struct {
+        name       string
+        uid        string
+        serviceErr error
+        wantStatus int
+    }{
+        {name: "not found", uid: "missing", serviceErr: models.OrderNotFoundError{OrderUID: "missing"}, wantStatus: http.StatusNotFound},
+        {name: "storage unavailable", uid: "uid-1", serviceErr: models.DatabaseError{Operation: "get", Err: errors.New("pq: password authentication failed")}, wantStatus: http.StatusServiceUnavailable},
+        {name: "unexpected error", uid: "uid-1", serviceErr: errors.New("pq: password authentication failed"), wantStatus: http.StatusInternalServerError},
+        {name: "uid too long", uid: strings.Repeat("a", maxOrderUIDLength+1), wantStatus: http.StatusBadRequest},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            mockService := &MockOrderService{}
+            handler := newPageHandler(t, mockService)
+            if tt.serviceErr != nil {
+                mockService.On("GetByUID", mock.Anything, tt.uid).Return(models.Order{}, tt.serviceErr)
+            }
+
+            req := httptest.NewRequest(http.MethodGet, "/?order_uid="+tt.uid, nil)
+            w := httptest.NewRecorder()
+            handler.GetOrderPage(w, req)
+
+            body := w.Body.String()
+            assert.Equal(t, tt.wantStatus, w.Code)
+            assert.Contains(t, body, html.EscapeString(errorViews[tt.wantStatus].Title))
+            // Текст внутренних ошибок не попадает на страницу
+            assert.NotContains(t, body, "pq:")
+            assert.NotContains(t, body, "order not found")
+            if tt.serviceErr == nil {
+                mockService.AssertNotCalled(t, "GetByUID", mock.Anything, mock.Anything)
+            }
+        })
+    }
+}
+
+func TestGetOrderPage_Detail(t *testing.T) {
+    mockService := &MockOrderService{}
+    handler := newPageHandler(t, mockService)
+
+    data, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "valid-order-template.json"))
+    require.NoError(t, err)
+    var order models.Order
+    require.NoError(t, json.Unmarshal(data, &order))
+
+    mockService.On("GetByUID", mock.Anything, order.OrderUID).Return(order, nil)
+
+    req := httptest.NewRequest(http.MethodGet, "/?order_uid="+order.OrderUID, nil)
+    w := httptest.NewRecorder()
+    handler.GetOrderPage(w, req)
+
+    assert.Equal(t, http.StatusOK, w.Code)
+    body := w.Body.String()
+    for _, want := range []string{
+        order.Delivery.Zip,
+        order.Delivery.Region,
+        order.Delivery.Email,
+        order.Payment.Provider,
+        order.Payment.Bank,
+        "26.11.2021 06:22:07 UTC", // payment_dt
+        "2389212",                 // nm_id
+        "202",                     // status
+        order.Items[0].Rid,
+    } {
+        assert.Contains(t, body, want)
+    }
+}
@@ -1,35 +1,94 @@
 package http
 
 import (
+    "net/http"
+
     mw "L0/internal/middleware"
+    "L0/internal/models"
 
     "github.com/go-chi/chi/v5"
     "github.com/go-chi/chi/v5/middleware"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
     httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(handler *OrderHandler, _ interface{}, rps float64, burst int, enabled bool) *chi.Mux {
+// allowedMethods - методы, которые перечисляются в заголовке Allow ответа 405
+var allowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// RouterOptions - необязательные части роутера. Нулевое значение: без
+// ограничения частоты и без аутентификации
+type RouterOptions struct {
+    Limiter        *mw.RateLimiter
+    Auth           *mw.Authenticator
+    ProtectSwagger bool // требовать ключ для Swagger UI
+}
+
+func NewRouter(handler *OrderHandler, health *HealthHandler, opts RouterOptions) *chi.Mux {
     router := chi.NewRouter()
 
+    router.Use(mw.Tracing())
     router.Use(middleware.Logger)
     router.Use(middleware.RequestID)
     router.Use(mw.NewCustomSlogLogger())
-    router.Use(middleware.Recoverer)
-    if enabled {
-        router.Use(mw.IPRateLimiter(rps, burst))
-    }
+    router.Use(mw.Metrics())
+    router.Use(mw.Recoverer())
+
+    // Ответы chi по умолчанию - обычный текст, заменяем их на общий формат ошибок
+    router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+        mw.WriteError(w, r, http.StatusNotFound, models.ErrCodeNotFound, "route not found")
+    })
+    router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+        for _, method := range allowedMethods {
+            if router.Match(chi.NewRouteContext(), method, r.URL.Path) {
+                w.Header().Add("Allow", method)
+            }
+        }
+        mw.WriteError(w, r, http.StatusMethodNotAllowed, models.ErrCodeMethodNotAllowed, "method "+r.Method+" is not allowed")
+    })
+
+    // Пробы оркестратора не должны упираться в rate limiter и аутентификацию
+    router.Get("/healthz", health.Liveness)
+    router.Get("/readyz", health.Readiness)
+
+    router.Group(func(router chi.Router) {
+        // Лимит до проверки ключа, чтобы ключи нельзя было перебирать без ограничений
+        if opts.Limiter != nil {
+            router.Use(opts.Limiter.Handler)
+        }
+
+        // role возвращает middleware проверки ключа с нужной ролью, без аутентификации - пустой
+        role := func(role models.Role) chi.Middlewares {
+            if opts.Auth == nil {
+                return nil
+            }
+            return chi.Middlewares{opts.Auth.Handler, mw.RequireRole(role)}
+        }
+
+        // Метрики Prometheus
+        router.With(role(models.RoleAdmin)...).Handle("/metrics", promhttp.Handler())
+
+        // Swagger UI
+        var swaggerAuth chi.Middlewares
+        if opts.ProtectSwagger {
+            swaggerAuth = role(models.RoleViewer)
+        }
+        router.With(swaggerAuth...).Get("/swagger/*", httpSwagger.Handler(
+            httpSwagger.URL("http://localhost:8081/swagger/doc.json"), // Исправил порт на 8081
+        ))
 
-    // Swagger UI
-    router.Get("/swagger/*", httpSwagger.Handler(
-        httpSwagger.URL("http://localhost:8081/swagger/doc.json"), // Исправил порт на 8081
-    ))
+        // Json API. viewer видит заказы со скрытыми персональными данными
+        viewer := router.With(role(models.RoleViewer)...)
+        viewer.Get("/order/{order_uid}", handler.GetOrderByPath)
+        viewer.Get("/orders", handler.ListOrders)
+        viewer.Get("/orders/stream", handler.StreamOrders)
 
-    
-    // Json API
-    router.Get("/order/{order_uid}", handler.GetOrderByPath)
+        support := router.With(role(models.RoleSupport)...)
+        support.Post("/orders", handler.CreateOrder)
+        support.Post("/orders/batch", handler.CreateOrdersBatch)
 
-    // Веб-интерфейс
-    router.Get("/", handler.GetOrderPage)
+        // Веб-интерфейс
+        viewer.Get("/", handler.GetOrderPage)
+    })
 
     return router
-}
\ No newline at end of file
+}
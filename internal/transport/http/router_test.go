@@ -0,0 +1,129 @@
+package http
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "L0/internal/config"
+    mw "L0/internal/middleware"
+    "L0/internal/models"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+)
+
+func TestRouter_ErrorEnvelope(t *testing.T) {
+    mockService := &MockOrderService{}
+    mockService.On("GetByUID", mock.Anything, "missing").Return(models.Order{}, models.OrderNotFoundError{OrderUID: "missing"})
+
+    router := NewRouter(&OrderHandler{service: mockService}, NewHealthHandler(0), RouterOptions{})
+
+    tests := []struct {
+        name       string
+        method     string
+        path       string
+        wantStatus int
+        wantCode   string
+    }{
+        {name: "handler error", method: http.MethodGet, path: "/order/missing", wantStatus: http.StatusNotFound, wantCode: models.ErrCodeNotFound},
+        {name: "unknown route", method: http.MethodGet, path: "/unknown", wantStatus: http.StatusNotFound, wantCode: models.ErrCodeNotFound},
+        {name: "wrong method", method: http.MethodDelete, path: "/orders", wantStatus: http.StatusMethodNotAllowed, wantCode: models.ErrCodeMethodNotAllowed},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            req := httptest.NewRequest(tt.method, tt.path, nil)
+            w := httptest.NewRecorder()
+            router.ServeHTTP(w, req)
+
+            require.Equal(t, tt.wantStatus, w.Code)
+            assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+            var resp models.ErrorResponse
+            require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+            assert.Equal(t, tt.wantCode, resp.Error.Code)
+            assert.NotEmpty(t, resp.Error.Message)
+            assert.NotEmpty(t, resp.Error.RequestID)
+        })
+    }
+
+    t.Run("allow header", func(t *testing.T) {
+        req := httptest.NewRequest(http.MethodDelete, "/orders", nil)
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+
+        assert.ElementsMatch(t, []string{http.MethodGet, http.MethodPost}, w.Header().Values("Allow"))
+    })
+}
+
+func TestRouter_Auth(t *testing.T) {
+    order := models.Order{
+        OrderUID: "uid-1",
+        Delivery: models.Delivery{Name: "Test Testov", Phone: "+9720000000", Email: "test@gmail.com"},
+    }
+    mockService := &MockOrderService{}
+    mockService.On("GetByUID", mock.Anything, "uid-1").Return(order, nil)
+
+    auth, err := mw.NewAuthenticator(config.Auth{Keys: config.APIKeys{
+        {Name: "dashboard", Role: "viewer", Key: "viewer-key"},
+        {Name: "support", Role: "support", Key: "support-key"},
+        {Name: "ops", Role: "admin", Key: "admin-key"},
+    }}, nil)
+    require.NoError(t, err)
+
+    router := NewRouter(&OrderHandler{service: mockService}, NewHealthHandler(0), RouterOptions{Auth: auth})
+
+    do := func(method, path, key string) *httptest.ResponseRecorder {
+        req := httptest.NewRequest(method, path, nil)
+        if key != "" {
+            req.Header.Set("X-API-Key", key)
+        }
+        w := httptest.NewRecorder()
+        router.ServeHTTP(w, req)
+        return w
+    }
+
+    t.Run("no key", func(t *testing.T) {
+        w := do(http.MethodGet, "/order/uid-1", "")
+        require.Equal(t, http.StatusUnauthorized, w.Code)
+
+        var resp models.ErrorResponse
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+        assert.Equal(t, models.ErrCodeUnauthorized, resp.Error.Code)
+    })
+
+    t.Run("health probes stay open", func(t *testing.T) {
+        assert.Equal(t, http.StatusOK, do(http.MethodGet, "/healthz", "").Code)
+    })
+
+    t.Run("viewer sees masked order", func(t *testing.T) {
+        w := do(http.MethodGet, "/order/uid-1", "viewer-key")
+        require.Equal(t, http.StatusOK, w.Code)
+
+        var got models.Order
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+        assert.Equal(t, "+972***0000", got.Delivery.Phone)
+        assert.Equal(t, "t***@gmail.com", got.Delivery.Email)
+    })
+
+    t.Run("support sees full order", func(t *testing.T) {
+        w := do(http.MethodGet, "/order/uid-1", "support-key")
+        require.Equal(t, http.StatusOK, w.Code)
+
+        var got models.Order
+        require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+        assert.Equal(t, order.Delivery, got.Delivery)
+    })
+
+    t.Run("viewer cannot create orders", func(t *testing.T) {
+        assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/orders", "viewer-key").Code)
+    })
+
+    t.Run("metrics are admin only", func(t *testing.T) {
+        assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/metrics", "support-key").Code)
+        assert.Equal(t, http.StatusOK, do(http.MethodGet, "/metrics", "admin-key").Code)
+    })
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "strconv"
+    "time"
+
+    "L0/internal/models"
+    "L0/internal/service"
+)
+
+// heartbeatInterval - как часто слать комментарий, чтобы прокси не закрывали простаивающее соединение
+const heartbeatInterval = 15 * time.Second
+
+// StreamOrders godoc
+// @Summary Stream new orders (SSE)
+// @Description Server-Sent Events: каждый заказ приходит событием order сразу после сохранения. id события можно передать в заголовке Last-Event-ID (или параметре last_event_id), чтобы получить пропущенные заказы из недавней истории. Если клиент не успевает читать, часть событий пропускается
+// @Tags orders
+// @Produce text/event-stream
+// @Param customer_id query string false "Только заказы покупателя"
+// @Param delivery_service query string false "Только заказы службы доставки"
+// @Param Last-Event-ID header int false "id последнего полученного события"
+// @Param last_event_id query int false "То же, что Last-Event-ID, для клиентов без управления заголовками"
+// @Success 200 {string} string "Поток событий"
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Security ApiKeyAuth
+// @Router /orders/stream [get]
+func (h *OrderHandler) StreamOrders(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+
+    lastEventID := r.Header.Get("Last-Event-ID")
+    if lastEventID == "" {
+        lastEventID = query.Get("last_event_id")
+    }
+
+    opts := service.SubscribeOptions{
+        CustomerID:      query.Get("customer_id"),
+        DeliveryService: query.Get("delivery_service"),
+    }
+    if lastEventID != "" {
+        id, err := strconv.ParseUint(lastEventID, 10, 64)
+        if err != nil {
+            writeErrorMessage(w, r, http.StatusBadRequest, "Last-Event-ID must be a non-negative integer")
+            return
+        }
+        opts.AfterID = id
+    }
+
+    // Поток живёт дольше WriteTimeout сервера
+    rc := http.NewResponseController(w)
+    if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+        slog.WarnContext(r.Context(), "failed to disable write deadline for SSE", "error", err)
+    }
+
+    view := h.orderView(r)
+    ctx := r.Context()
+    missed, events := h.service.Subscribe(ctx, opts)
+
+    w.Header().Set("Content-Type", "text/event-stream")
+    w.Header().Set("Cache-Control", "no-cache")
+    w.Header().Set("Connection", "keep-alive")
+    w.Header().Set("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+    w.WriteHeader(http.StatusOK)
+
+    for _, event := range missed {
+        if err := writeEvent(ctx, w, event, view); err != nil {
+            return
+        }
+    }
+    if err := rc.Flush(); err != nil {
+        slog.ErrorContext(ctx, "SSE is not supported by response writer", "error", err)
+        return
+    }
+
+    heartbeat := time.NewTicker(heartbeatInterval)
+    defer heartbeat.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-h.closing:
+            return
+        case <-heartbeat.C:
+            if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+                return
+            }
+        case event, ok := <-events:
+            if !ok {
+                return
+            }
+            if err := writeEvent(ctx, w, event, view); err != nil {
+                return
+            }
+        }
+        if err := rc.Flush(); err != nil {
+            return
+        }
+    }
+}
+
+// CloseStreams завершает открытые SSE потоки. Вызывается через RegisterOnShutdown:
+// иначе http.Server.Shutdown ждал бы их до таймаута.
+func (h *OrderHandler) CloseStreams() {
+    h.closeOnce.Do(func() { close(h.closing) })
+}
+
+func writeEvent(ctx context.Context, w http.ResponseWriter, event service.OrderEvent, view func(models.Order) models.Order) error {
+    data, err := json.Marshal(view(event.Order))
+    if err != nil {
+        slog.ErrorContext(ctx, "failed to encode order event", "error", err, "order_uid", event.Order.OrderUID)
+        return nil
+    }
+    _, err = fmt.Fprintf(w, "id: %d\nevent: order\ndata: %s\n\n", event.ID, data)
+    return err
+}
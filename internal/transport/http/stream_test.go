@@ -0,0 +1,99 @@
+package http
+
+import (
+    "bufio"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "L0/internal/models"
+    "L0/internal/service"
+
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+)
+
+// readEvent читает одно SSE событие (строки до пустой)
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+    t.Helper()
+    var lines []string
+    for {
+        line, err := reader.ReadString('\n')
+        require.NoError(t, err)
+        line = strings.TrimSuffix(line, "\n")
+        if line == "" {
+            return lines
+        }
+        lines = append(lines, line)
+    }
+}
+
+func TestStreamOrders_ResumesAndStreams(t *testing.T) {
+    mockService := &MockOrderService{}
+    events := make(chan service.OrderEvent, 1)
+    missed := []service.OrderEvent{{ID: 6, Order: models.Order{OrderUID: "uid-6", CustomerID: "alice"}}}
+    opts := service.SubscribeOptions{AfterID: 5, CustomerID: "alice", DeliveryService: "meest"}
+    mockService.On("Subscribe", mock.Anything, opts).Return(missed, (<-chan service.OrderEvent)(events))
+
+    handler := &OrderHandler{service: mockService, closing: make(chan struct{})}
+    server := httptest.NewServer(http.HandlerFunc(handler.StreamOrders))
+    defer server.Close()
+
+    req, err := http.NewRequest(http.MethodGet, server.URL+"?customer_id=alice&delivery_service=meest", nil)
+    require.NoError(t, err)
+    req.Header.Set("Last-Event-ID", "5")
+
+    resp, err := http.DefaultClient.Do(req)
+    require.NoError(t, err)
+    defer resp.Body.Close()
+
+    assert.Equal(t, http.StatusOK, resp.StatusCode)
+    assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+    reader := bufio.NewReader(resp.Body)
+
+    // Сначала пропущенное событие из истории
+    lines := readEvent(t, reader)
+    require.Len(t, lines, 3)
+    assert.Equal(t, "id: 6", lines[0])
+    assert.Equal(t, "event: order", lines[1])
+    assert.Contains(t, lines[2], `"order_uid":"uid-6"`)
+
+    events <- service.OrderEvent{ID: 7, Order: models.Order{OrderUID: "uid-7", CustomerID: "alice"}}
+    lines = readEvent(t, reader)
+    require.Len(t, lines, 3)
+    assert.Equal(t, "id: 7", lines[0])
+    assert.Contains(t, lines[2], `"order_uid":"uid-7"`)
+
+    // Остановка сервера завершает поток
+    handler.CloseStreams()
+    done := make(chan error, 1)
+    go func() {
+        _, err := reader.ReadString('\n')
+        done <- err
+    }()
+    select {
+    case err := <-done:
+        assert.Error(t, err)
+    case <-time.After(time.Second):
+        t.Fatal("stream was not closed on shutdown")
+    }
+
+    mockService.AssertExpectations(t)
+}
+
+func TestStreamOrders_InvalidLastEventID(t *testing.T) {
+    mockService := &MockOrderService{}
+    handler := &OrderHandler{service: mockService}
+
+    req := httptest.NewRequest(http.MethodGet, "/orders/stream?last_event_id=abc", nil)
+    w := httptest.NewRecorder()
+
+    handler.StreamOrders(w, req)
+
+    assert.Equal(t, http.StatusBadRequest, w.Code)
+    mockService.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,122 @@
+package kafka
+
+import (
+    "context"
+    "log/slog"
+
+    "L0/internal/metrics"
+    "L0/internal/models"
+
+    "github.com/cenkalti/backoff/v4"
+    "github.com/segmentio/kafka-go"
+)
+
+// runBatch читает сообщения пачками до KAFKA_BATCH_SIZE штук (или сколько успело
+// прийти за KAFKA_BATCH_TIMEOUT) и сохраняет их одной транзакцией
+func (c *Consumer) runBatch(ctx context.Context) {
+    slog.Info("Starting Kafka consumer in batch mode...", "batch_size", c.cfg.Kafka.BatchSize)
+
+    for {
+        select {
+        case <-ctx.Done():
+            slog.Info("Consumer context cancelled, stopping...")
+            return
+        default:
+        }
+
+        msgs, err := c.fetchBatch(ctx)
+        if err != nil {
+            slog.Error("failed to read message after retries", "error", err)
+            continue
+        }
+
+        if err := c.processBatch(ctx, msgs); err != nil {
+            slog.Error("batch left uncommitted", "error", err, "messages", len(msgs))
+            continue
+        }
+
+        c.commit(ctx, msgs...)
+    }
+}
+
+// fetchBatch ждёт первое сообщение без ограничения по времени,
+// а остальные добирает в пределах BatchTimeout
+func (c *Consumer) fetchBatch(ctx context.Context) ([]kafka.Message, error) {
+    first, err := c.fetch(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    msgs := []kafka.Message{first}
+
+    fetchCtx, cancel := context.WithTimeout(ctx, c.cfg.Kafka.BatchTimeout)
+    defer cancel()
+
+    for len(msgs) < c.cfg.Kafka.BatchSize {
+        m, err := c.reader.FetchMessage(fetchCtx)
+        if err != nil {
+            break
+        }
+        msgs = append(msgs, m)
+    }
+
+    return msgs, nil
+}
+
+// processBatch сохраняет валидные заказы пачки через CreateBatch.
+// Ошибка возвращается только тогда, когда коммитить offset'ы пачки нельзя.
+func (c *Consumer) processBatch(ctx context.Context, msgs []kafka.Message) error {
+    ctx, span := startBatchSpan(ctx, c.cfg.Kafka.Topic, msgs)
+    defer span.End()
+
+    orders := make([]models.Order, 0, len(msgs))
+    valid := make([]kafka.Message, 0, len(msgs))
+
+    for _, m := range msgs {
+        order, ok, err := c.decode(ctx, m)
+        if err != nil {
+            return err
+        }
+        if ok {
+            orders = append(orders, order)
+            valid = append(valid, m)
+        }
+    }
+
+    if len(orders) == 0 {
+        return nil
+    }
+
+    var results []error
+    saveOperation := func() error {
+        var err error
+        results, err = c.service.CreateBatch(ctx, orders)
+        return err
+    }
+
+    if err := backoff.RetryNotify(saveOperation, backoff.WithContext(saveBackOff(), ctx), notifyRetry); err != nil {
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+        // Пачка целиком не сохранилась - каждое сообщение уходит в DLQ
+        results = make([]error, len(orders))
+        for i := range results {
+            results[i] = err
+        }
+    }
+
+    stored := 0
+    for i, err := range results {
+        if err == nil {
+            metrics.KafkaMessagesProcessed.Inc()
+            stored++
+            continue
+        }
+        if err := c.handleSaveError(ctx, valid[i], orders[i], err); err != nil {
+            return err
+        }
+    }
+
+    slog.InfoContext(ctx, "Successfully processed batch", "messages", len(msgs), "stored", stored)
+    return nil
+}
@@ -0,0 +1,103 @@
+package kafka
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "L0/internal/models"
+
+    "github.com/segmentio/kafka-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+)
+
+func batchMessage(t *testing.T, uid string, offset int64) kafka.Message {
+    t.Helper()
+
+    template, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "valid-order-template.json"))
+    require.NoError(t, err)
+
+    var order models.Order
+    require.NoError(t, json.Unmarshal(template, &order))
+    order.OrderUID = uid
+    order.Payment.Transaction = uid
+
+    value, err := json.Marshal(order)
+    require.NoError(t, err)
+    return kafka.Message{Topic: "orders", Offset: offset, Value: value}
+}
+
+func TestConsumer_ProcessBatch_PerOrderResults(t *testing.T) {
+    mockService := &MockOrderService{}
+    consumer, _, writer := newTestConsumer(mockService)
+
+    msgs := []kafka.Message{
+        batchMessage(t, "uid-1", 0),
+        {Topic: "orders", Offset: 1, Value: []byte(`{"invalid": json}`)},
+        batchMessage(t, "uid-2", 2),
+        batchMessage(t, "uid-3", 3),
+    }
+
+    mockService.On("CreateBatch", mock.Anything, mock.MatchedBy(func(orders []models.Order) bool {
+        return len(orders) == 3
+    })).Return([]error{
+        nil,
+        models.OrderAlreadyExistsError{OrderUID: "uid-2"},
+        models.DatabaseError{Operation: "create", Err: fmt.Errorf("boom")},
+    }, nil).Once()
+
+    err := consumer.processBatch(context.Background(), msgs)
+    require.NoError(t, err)
+
+    // В DLQ уходят битое сообщение и заказ с ошибкой хранилища, дубликат пропускается
+    require.Len(t, writer.written, 2)
+    assert.Equal(t, errorClassDecode, headerValue(writer.written[0], headerDLQErrorClass))
+    assert.Equal(t, errorClassStorage, headerValue(writer.written[1], headerDLQErrorClass))
+    assert.Equal(t, "3", headerValue(writer.written[1], headerDLQSourceOffset))
+
+    mockService.AssertExpectations(t)
+}
+
+func TestConsumer_RunBatch_CommitsAllMessages(t *testing.T) {
+    mockService := &MockOrderService{}
+    mockService.On("CreateBatch", mock.Anything, mock.Anything).Return(func(_ context.Context, orders []models.Order) []error {
+        return make([]error, len(orders))
+    }, nil)
+
+    consumer, reader, writer := newTestConsumer(mockService)
+    consumer.cfg.Kafka.BatchSize = 3
+    consumer.cfg.Kafka.BatchTimeout = 10 * time.Millisecond
+    for i := int64(0); i < 7; i++ {
+        reader.messages = append(reader.messages, batchMessage(t, fmt.Sprintf("uid-%d", i), i))
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+    go func() {
+        consumer.Run(ctx)
+        close(done)
+    }()
+
+    assert.Eventually(t, func() bool {
+        return len(reader.committedMessages()) == 7
+    }, 5*time.Second, 10*time.Millisecond)
+
+    cancel()
+    <-done
+
+    assert.Empty(t, writer.written)
+    calls := 0
+    for _, call := range mockService.Calls {
+        if call.Method == "CreateBatch" {
+            assert.LessOrEqual(t, len(call.Arguments.Get(1).([]models.Order)), 3)
+            calls++
+        }
+    }
+    assert.GreaterOrEqual(t, calls, 3)
+}
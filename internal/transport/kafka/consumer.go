@@ -2,11 +2,12 @@ package kafka
 
 import (
     "context"
-    "encoding/json"
+    "errors"
     "log/slog"
     "time"
 
     "L0/internal/config"
+    "L0/internal/metrics"
     "L0/internal/models"
     "L0/internal/service"
 
@@ -14,9 +15,24 @@ import (
     "github.com/segmentio/kafka-go"
 )
 
+// messageReader - часть kafka.Reader, которая нужна консьюмеру
+type messageReader interface {
+    FetchMessage(ctx context.Context) (kafka.Message, error)
+    CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+    Stats() kafka.ReaderStats
+    Close() error
+}
+
+// messageWriter - часть kafka.Writer, которая нужна для DLQ
+type messageWriter interface {
+    WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+    Close() error
+}
+
 type Consumer struct {
     service service.OrderService
-    reader  *kafka.Reader
+    reader  messageReader
+    dlq     messageWriter
     cfg     *config.Config
 }
 
@@ -29,14 +45,34 @@ func NewConsumer(srv service.OrderService, cfg *config.Config) *Consumer {
         MaxBytes: 10e6, // 10мб
     })
 
+    dlq := &kafka.Writer{
+        Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+        Topic:        cfg.Kafka.DLQTopic,
+        Balancer:     &kafka.Hash{},
+        RequiredAcks: kafka.RequireAll,
+    }
+
     return &Consumer{
         service: srv,
         reader:  r,
+        dlq:     dlq,
         cfg:     cfg,
     }
 }
 
+// Run выбирает режим: пул воркеров при KAFKA_WORKERS > 1, пакетный при
+// KAFKA_BATCH_SIZE > 1 или по одному сообщению. Одновременно оба не
+// включаются, это проверяет config.Validate
 func (c *Consumer) Run(ctx context.Context) {
+    if c.cfg.Kafka.Workers > 1 {
+        c.runPool(ctx)
+        return
+    }
+    if c.cfg.Kafka.BatchSize > 1 {
+        c.runBatch(ctx)
+        return
+    }
+
     slog.Info("Starting Kafka consumer...")
 
     for {
@@ -47,54 +83,138 @@ func (c *Consumer) Run(ctx context.Context) {
         default:
         }
 
-        var m kafka.Message
-        var err error
-
-        operation := func() error {
-            m, err = c.reader.ReadMessage(ctx)
-            if err != nil {
-                slog.Warn("failed to read message from kafka, retrying...", "error", err)
-                return err
-            }
-            return nil
-        }
-
-        bo := backoff.NewExponentialBackOff()
-        bo.MaxElapsedTime = 30 * time.Second
-        bo.InitialInterval = 1 * time.Second
-        bo.MaxInterval = 5 * time.Second
-
-        if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
+        m, err := c.fetch(ctx)
+        if err != nil {
             slog.Error("failed to read message after retries", "error", err)
             continue
         }
 
-        var order models.Order
-        if err := json.Unmarshal(m.Value, &order); err != nil {
-            slog.Error("failed to unmarshal order", "error", err, "message_value", string(m.Value))
+        if err := c.process(ctx, m); err != nil {
+            slog.Error("message left uncommitted", "error", err, "partition", m.Partition, "offset", m.Offset)
             continue
         }
 
-        saveOperation := func() error {
-            return c.service.Create(ctx, order)
-        }
+        c.commit(ctx, m)
+    }
+}
 
-        saveBo := backoff.NewExponentialBackOff()
-        saveBo.MaxElapsedTime = 10 * time.Second
-        saveBo.InitialInterval = 500 * time.Millisecond
-        saveBo.MaxInterval = 2 * time.Second
+func (c *Consumer) fetch(ctx context.Context) (kafka.Message, error) {
+    var m kafka.Message
+    var err error
 
-        if err := backoff.Retry(saveOperation, backoff.WithContext(saveBo, ctx)); err != nil {
-            slog.Error("failed to save order after retries", "error", err, "order_uid", order.OrderUID)
-            continue
+    operation := func() error {
+        m, err = c.reader.FetchMessage(ctx)
+        if err != nil {
+            slog.Warn("failed to read message from kafka, retrying...", "error", err)
+            return err
         }
+        return nil
+    }
 
-        slog.Info("Successfully processed order", "order_uid", order.OrderUID)
+    bo := backoff.NewExponentialBackOff()
+    bo.MaxElapsedTime = 30 * time.Second
+    bo.InitialInterval = 1 * time.Second
+    bo.MaxInterval = 5 * time.Second
 
-        if err := c.reader.CommitMessages(ctx, m); err != nil {
-            slog.Error("failed to commit message", "error", err)
+    if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
+        return kafka.Message{}, err
+    }
+    return m, nil
+}
+
+// process сохраняет заказ из сообщения. Сообщения, которые не удалось обработать,
+// уходят в DLQ. Ошибка возвращается только тогда, когда коммитить offset нельзя.
+func (c *Consumer) process(ctx context.Context, m kafka.Message) error {
+    ctx, span := startMessageSpan(ctx, m)
+    defer span.End()
+
+    order, ok, err := c.decode(ctx, m)
+    if !ok {
+        return err
+    }
+
+    saveOperation := func() error {
+        err := c.service.Create(ctx, order)
+        var existsErr models.OrderAlreadyExistsError
+        if errors.As(err, &existsErr) {
+            return backoff.Permanent(err)
         }
+        return err
+    }
+
+    if err := backoff.RetryNotify(saveOperation, backoff.WithContext(saveBackOff(), ctx), notifyRetry); err != nil {
+        return c.handleSaveError(ctx, m, order, err)
     }
+
+    metrics.KafkaMessagesProcessed.Inc()
+    slog.InfoContext(ctx, "Successfully processed order", "order_uid", order.OrderUID)
+    return nil
+}
+
+// decode разбирает и валидирует сообщение. Если заказ невалиден, сообщение
+// отправляется в DLQ и ok == false; err при этом - ошибка публикации в DLQ.
+func (c *Consumer) decode(ctx context.Context, m kafka.Message) (order models.Order, ok bool, err error) {
+    order, err = models.DecodeOrder(m.Value)
+    if err == nil {
+        return order, true, nil
+    }
+
+    failSpan(ctx, err)
+
+    var validationErr models.ValidationError
+    if errors.As(err, &validationErr) {
+        slog.WarnContext(ctx, "order failed validation", "error", err, "order_uid", order.OrderUID)
+        metrics.KafkaMessagesFailed.WithLabelValues(errorClassValidation).Inc()
+        return order, false, c.sendToDLQ(ctx, m, errorClassValidation, err)
+    }
+
+    slog.ErrorContext(ctx, "failed to unmarshal order", "error", err, "message_value", string(m.Value))
+    metrics.KafkaMessagesFailed.WithLabelValues(errorClassDecode).Inc()
+    return order, false, c.sendToDLQ(ctx, m, errorClassDecode, err)
+}
+
+// handleSaveError решает судьбу сообщения, заказ из которого не удалось сохранить
+func (c *Consumer) handleSaveError(ctx context.Context, m kafka.Message, order models.Order, err error) error {
+    // При остановке сервиса сообщение не трогаем - его перечитают после рестарта
+    if ctx.Err() != nil {
+        return ctx.Err()
+    }
+
+    // Повторная доставка уже сохранённого заказа - обычная ситуация для at-least-once
+    var existsErr models.OrderAlreadyExistsError
+    if errors.As(err, &existsErr) {
+        slog.WarnContext(ctx, "order already exists, skipping message", "order_uid", order.OrderUID)
+        return nil
+    }
+
+    failSpan(ctx, err)
+    slog.ErrorContext(ctx, "failed to save order after retries", "error", err, "order_uid", order.OrderUID)
+    metrics.KafkaMessagesFailed.WithLabelValues(errorClassStorage).Inc()
+    return c.sendToDLQ(ctx, m, errorClassStorage, err)
+}
+
+func saveBackOff() backoff.BackOff {
+    bo := backoff.NewExponentialBackOff()
+    bo.MaxElapsedTime = 10 * time.Second
+    bo.InitialInterval = 500 * time.Millisecond
+    bo.MaxInterval = 2 * time.Second
+    return bo
+}
+
+func notifyRetry(error, time.Duration) {
+    metrics.KafkaMessagesRetried.Inc()
+}
+
+func (c *Consumer) commit(ctx context.Context, msgs ...kafka.Message) {
+    // Уже обработанные сообщения коммитим и во время остановки
+    commitCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.cfg.Kafka.CommitTimeout)
+    defer cancel()
+
+    start := time.Now()
+    if err := c.reader.CommitMessages(commitCtx, msgs...); err != nil {
+        slog.Error("failed to commit message", "error", err)
+    }
+    metrics.KafkaCommitDuration.Observe(time.Since(start).Seconds())
 }
 
 func (c *Consumer) Close() {
@@ -105,5 +225,9 @@ func (c *Consumer) Close() {
         slog.Error("failed to close kafka reader", "error", err)
     }
 
+    if err := c.dlq.Close(); err != nil {
+        slog.Error("failed to close DLQ writer", "error", err)
+    }
+
     slog.Info("Kafka consumer closed.")
-}
\ No newline at end of file
+}
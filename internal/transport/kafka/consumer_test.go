@@ -3,13 +3,20 @@ package kafka
 import (
     "context"
     "encoding/json"
+    "os"
+    "path/filepath"
+    "sync"
     "testing"
+    "time"
 
+    "L0/internal/config"
     "L0/internal/models"
     "L0/internal/service"
 
+    "github.com/segmentio/kafka-go"
     "github.com/stretchr/testify/assert"
     "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
 )
 
 // Мок для сервиса заказов
@@ -20,6 +27,15 @@ type MockOrderService struct {
 // Проверка, что MockOrderService реализует интерфейс service.OrderService
 var _ service.OrderService = (*MockOrderService)(nil)
 
+func (m *MockOrderService) CreateBatch(ctx context.Context, orders []models.Order) ([]error, error) {
+    args := m.Called(ctx, orders)
+    if fn, ok := args.Get(0).(func(context.Context, []models.Order) []error); ok {
+        return fn(ctx, orders), args.Error(1)
+    }
+    results, _ := args.Get(0).([]error)
+    return results, args.Error(1)
+}
+
 func (m *MockOrderService) GetByUID(ctx context.Context, uid string) (models.Order, error) {
     args := m.Called(ctx, uid)
     order, _ := args.Get(0).(models.Order)
@@ -37,11 +53,28 @@ func (m *MockOrderService) GetLatest(ctx context.Context, limit int) ([]models.O
     return orders, args.Error(1)
 }
 
+func (m *MockOrderService) List(ctx context.Context, filter models.OrderFilter) (models.OrderPage, error) {
+    args := m.Called(ctx, filter)
+    page, _ := args.Get(0).(models.OrderPage)
+    return page, args.Error(1)
+}
+
 func (m *MockOrderService) WarmUpCache(ctx context.Context) error {
     args := m.Called(ctx)
     return args.Error(0)
 }
 
+func (m *MockOrderService) SetCacheTTL(ttl time.Duration) {
+    m.Called(ttl)
+}
+
+func (m *MockOrderService) Subscribe(ctx context.Context, opts service.SubscribeOptions) ([]service.OrderEvent, <-chan service.OrderEvent) {
+    args := m.Called(ctx, opts)
+    missed, _ := args.Get(0).([]service.OrderEvent)
+    ch, _ := args.Get(1).(<-chan service.OrderEvent)
+    return missed, ch
+}
+
 func TestConsumer_ProcessValidOrderJSON(t *testing.T) {
     mockService := &MockOrderService{}
 
@@ -80,4 +113,146 @@ func TestConsumer_ProcessInvalidJSON(t *testing.T) {
     assert.Error(t, err)
     // Проверка, что сервис не вызывается при ошибке парсинга
     mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// fakeReader отдаёт заранее заданные сообщения и запоминает закоммиченные,
+// fakeWriter запоминает опубликованные сообщения
+type fakeReader struct {
+    mu        sync.Mutex
+    messages  []kafka.Message
+    committed []kafka.Message
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+    r.mu.Lock()
+    if len(r.messages) > 0 {
+        m := r.messages[0]
+        r.messages = r.messages[1:]
+        r.mu.Unlock()
+        return m, nil
+    }
+    r.mu.Unlock()
+
+    <-ctx.Done()
+    return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.committed = append(r.committed, msgs...)
+    return nil
+}
+
+func (r *fakeReader) committedMessages() []kafka.Message {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return append([]kafka.Message(nil), r.committed...)
+}
+
+func (r *fakeReader) Stats() kafka.ReaderStats { return kafka.ReaderStats{} }
+
+func (r *fakeReader) Close() error { return nil }
+
+type fakeWriter struct {
+    written []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+    w.written = append(w.written, msgs...)
+    return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func newTestConsumer(srv service.OrderService) (*Consumer, *fakeReader, *fakeWriter) {
+    reader := &fakeReader{}
+    writer := &fakeWriter{}
+    cfg := &config.Config{Kafka: config.Kafka{CommitTimeout: time.Second}}
+    return &Consumer{service: srv, reader: reader, dlq: writer, cfg: cfg}, reader, writer
+}
+
+func headerValue(m kafka.Message, key string) string {
+    for _, h := range m.Headers {
+        if h.Key == key {
+            return string(h.Value)
+        }
+    }
+    return ""
+}
+
+func TestConsumer_Process_InvalidJSONGoesToDLQ(t *testing.T) {
+    mockService := &MockOrderService{}
+    consumer, _, writer := newTestConsumer(mockService)
+
+    msg := kafka.Message{Topic: "orders", Partition: 2, Offset: 42, Key: []byte("key"), Value: []byte(`{"invalid": json}`)}
+
+    err := consumer.process(context.Background(), msg)
+    require.NoError(t, err)
+
+    require.Len(t, writer.written, 1)
+    dlqMsg := writer.written[0]
+    assert.Equal(t, msg.Value, dlqMsg.Value)
+    assert.Equal(t, msg.Key, dlqMsg.Key)
+    assert.Equal(t, errorClassDecode, headerValue(dlqMsg, headerDLQErrorClass))
+    assert.Equal(t, "orders", headerValue(dlqMsg, headerDLQSourceTopic))
+    assert.Equal(t, "2", headerValue(dlqMsg, headerDLQSourcePartition))
+    assert.Equal(t, "42", headerValue(dlqMsg, headerDLQSourceOffset))
+    assert.NotEmpty(t, headerValue(dlqMsg, headerDLQReason))
+    assert.NotEmpty(t, headerValue(dlqMsg, headerDLQTimestamp))
+
+    mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestConsumer_Process_InvalidOrderGoesToDLQ(t *testing.T) {
+    mockService := &MockOrderService{}
+    consumer, _, writer := newTestConsumer(mockService)
+
+    data, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "error-negative-amount.json"))
+    require.NoError(t, err)
+
+    err = consumer.process(context.Background(), kafka.Message{Value: data})
+    require.NoError(t, err)
+
+    require.Len(t, writer.written, 1)
+    assert.Equal(t, errorClassValidation, headerValue(writer.written[0], headerDLQErrorClass))
+    assert.Contains(t, headerValue(writer.written[0], headerDLQReason), "payment.amount")
+
+    mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestConsumer_Process_ValidOrderSaved(t *testing.T) {
+    mockService := &MockOrderService{}
+    consumer, _, writer := newTestConsumer(mockService)
+
+    data, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "valid-order-template.json"))
+    require.NoError(t, err)
+
+    mockService.On("Create", mock.Anything, mock.MatchedBy(func(order models.Order) bool {
+        return order.OrderUID == "b563feb7b2b84b6test"
+    })).Return(nil).Once()
+
+    err = consumer.process(context.Background(), kafka.Message{Value: data})
+    require.NoError(t, err)
+
+    assert.Empty(t, writer.written)
+    mockService.AssertExpectations(t)
+}
+
+func TestConsumer_Process_DuplicateOrderSkipped(t *testing.T) {
+    mockService := &MockOrderService{}
+    consumer, _, writer := newTestConsumer(mockService)
+
+    data, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "valid-order-template.json"))
+    require.NoError(t, err)
+
+    mockService.On("Create", mock.Anything, mock.Anything).
+        Return(models.OrderAlreadyExistsError{OrderUID: "b563feb7b2b84b6test"}).Once()
+
+    err = consumer.process(context.Background(), kafka.Message{Value: data})
+    require.NoError(t, err)
+
+    // Дубликат не ретраится и не отправляется в DLQ
+    assert.Empty(t, writer.written)
+    mockService.AssertNumberOfCalls(t, "Create", 1)
 }
\ No newline at end of file
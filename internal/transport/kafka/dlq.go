@@ -0,0 +1,78 @@
+package kafka
+
+import (
+    "context"
+    "log/slog"
+    "strconv"
+    "time"
+
+    "github.com/cenkalti/backoff/v4"
+    "github.com/segmentio/kafka-go"
+)
+
+// Классы ошибок, с которыми сообщение попадает в DLQ
+const (
+    errorClassDecode     = "decode"
+    errorClassValidation = "validation"
+    errorClassStorage    = "storage"
+)
+
+// Заголовки, которые добавляются к сообщению в DLQ
+const (
+    headerDLQReason          = "x-dlq-reason"
+    headerDLQErrorClass      = "x-dlq-error-class"
+    headerDLQSourceTopic     = "x-dlq-source-topic"
+    headerDLQSourcePartition = "x-dlq-source-partition"
+    headerDLQSourceOffset    = "x-dlq-source-offset"
+    headerDLQTimestamp       = "x-dlq-timestamp"
+)
+
+// sendToDLQ публикует исходное сообщение в DLQ-топик.
+// Публикация повторяется до успеха или отмены контекста: если сообщение не попало в DLQ,
+// коммитить его offset нельзя, иначе оно потеряется.
+func (c *Consumer) sendToDLQ(ctx context.Context, m kafka.Message, class string, reason error) error {
+    msg := dlqMessage(m, class, reason, time.Now())
+
+    operation := func() error {
+        if err := c.dlq.WriteMessages(ctx, msg); err != nil {
+            slog.WarnContext(ctx, "failed to publish message to DLQ, retrying...", "error", err)
+            return err
+        }
+        return nil
+    }
+
+    bo := backoff.NewExponentialBackOff()
+    bo.MaxElapsedTime = 0 // до отмены контекста
+    bo.InitialInterval = 500 * time.Millisecond
+    bo.MaxInterval = 5 * time.Second
+
+    if err := backoff.Retry(operation, backoff.WithContext(bo, ctx)); err != nil {
+        return err
+    }
+
+    slog.InfoContext(ctx, "Message sent to DLQ",
+        "error_class", class,
+        "partition", m.Partition,
+        "offset", m.Offset,
+    )
+    return nil
+}
+
+func dlqMessage(m kafka.Message, class string, reason error, now time.Time) kafka.Message {
+    headers := make([]kafka.Header, 0, len(m.Headers)+6)
+    headers = append(headers, m.Headers...)
+    headers = append(headers,
+        kafka.Header{Key: headerDLQReason, Value: []byte(reason.Error())},
+        kafka.Header{Key: headerDLQErrorClass, Value: []byte(class)},
+        kafka.Header{Key: headerDLQSourceTopic, Value: []byte(m.Topic)},
+        kafka.Header{Key: headerDLQSourcePartition, Value: []byte(strconv.Itoa(m.Partition))},
+        kafka.Header{Key: headerDLQSourceOffset, Value: []byte(strconv.FormatInt(m.Offset, 10))},
+        kafka.Header{Key: headerDLQTimestamp, Value: []byte(now.UTC().Format(time.RFC3339Nano))},
+    )
+
+    return kafka.Message{
+        Key:     m.Key,
+        Value:   m.Value,
+        Headers: headers,
+    }
+}
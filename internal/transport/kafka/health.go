@@ -0,0 +1,40 @@
+package kafka
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "github.com/segmentio/kafka-go"
+)
+
+// HealthCheck проверяет, что хотя бы один брокер доступен и лаг консьюмера
+// не превышает KAFKA_MAX_LAG (0 - без ограничения)
+func (c *Consumer) HealthCheck(ctx context.Context) (map[string]any, error) {
+    lag := c.reader.Stats().Lag
+    details := map[string]any{"lag": lag}
+
+    if err := c.pingBrokers(ctx); err != nil {
+        return details, err
+    }
+
+    if maxLag := c.cfg.Kafka.MaxLag; maxLag > 0 && lag > maxLag {
+        return details, fmt.Errorf("consumer lag %d exceeds %d", lag, maxLag)
+    }
+
+    return details, nil
+}
+
+func (c *Consumer) pingBrokers(ctx context.Context) error {
+    var errs []error
+    for _, broker := range c.cfg.Kafka.Brokers {
+        conn, err := kafka.DialContext(ctx, "tcp", broker)
+        if err != nil {
+            errs = append(errs, err)
+            continue
+        }
+        _ = conn.Close()
+        return nil
+    }
+    return fmt.Errorf("no kafka broker reachable: %w", errors.Join(errs...))
+}
@@ -0,0 +1,161 @@
+package kafka
+
+import (
+    "context"
+    "log/slog"
+    "strconv"
+    "time"
+
+    "L0/internal/config"
+    "L0/internal/metrics"
+    "L0/internal/models"
+    "L0/internal/repository"
+
+    "github.com/cenkalti/backoff/v4"
+    "github.com/segmentio/kafka-go"
+)
+
+// Заголовки событий outbox
+const (
+    headerEventType = "x-event-type"
+    headerOutboxID  = "x-outbox-id"
+)
+
+// OutboxRelay публикует события из transactional outbox в OUTBOX_TOPIC.
+// Доставка at-least-once: событие отмечается отправленным только после
+// подтверждения Kafka, поэтому при сбое между публикацией и отметкой оно
+// будет опубликовано ещё раз. Получатели отбрасывают повторы по x-outbox-id
+type OutboxRelay struct {
+    store  repository.OutboxRepository
+    writer messageWriter
+    cfg    config.Outbox
+}
+
+func NewOutboxRelay(store repository.OutboxRepository, cfg *config.Config) *OutboxRelay {
+    w := &kafka.Writer{
+        Addr:         kafka.TCP(cfg.Kafka.Brokers...),
+        Topic:        cfg.Outbox.Topic,
+        Balancer:     &kafka.Hash{}, // события одного заказа попадают в одну партицию
+        RequiredAcks: kafka.RequireAll,
+        BatchSize:    cfg.Outbox.BatchSize,
+        BatchTimeout: 10 * time.Millisecond, // пачку собирает сам relay, ждать добора незачем
+    }
+
+    return &OutboxRelay{
+        store:  store,
+        writer: w,
+        cfg:    cfg.Outbox,
+    }
+}
+
+// Run публикует новые события каждые OUTBOX_POLL_INTERVAL, после ошибки -
+// с экспоненциальной задержкой, и раз в OUTBOX_CLEANUP_INTERVAL удаляет
+// отправленные события старше OUTBOX_RETENTION
+func (r *OutboxRelay) Run(ctx context.Context) {
+    slog.Info("Starting outbox relay...", "topic", r.cfg.Topic)
+
+    cleanup := time.NewTicker(r.cfg.CleanupInterval)
+    defer cleanup.Stop()
+
+    poll := time.NewTimer(0)
+    defer poll.Stop()
+
+    bo := relayBackOff()
+
+    for {
+        select {
+        case <-ctx.Done():
+            slog.Info("Outbox relay context cancelled, stopping...")
+            return
+
+        case <-cleanup.C:
+            r.cleanup(ctx)
+
+        case <-poll.C:
+            wait := r.cfg.PollInterval
+            if err := r.drain(ctx); err != nil {
+                if ctx.Err() != nil {
+                    continue
+                }
+                wait = bo.NextBackOff()
+                metrics.OutboxPublishErrors.Inc()
+                slog.Warn("failed to publish outbox events, retrying...", "error", err, "retry_in", wait)
+            } else {
+                bo.Reset()
+            }
+            poll.Reset(wait)
+        }
+    }
+}
+
+// drain публикует события пачками, пока не разберёт все накопившиеся
+func (r *OutboxRelay) drain(ctx context.Context) error {
+    for {
+        n, err := r.store.PublishOutbox(ctx, r.cfg.BatchSize, r.publish)
+        if err != nil {
+            return err
+        }
+        if n > 0 {
+            metrics.OutboxPublished.Add(float64(n))
+            slog.Debug("Published outbox events", "count", n)
+        }
+        if n < r.cfg.BatchSize {
+            return nil
+        }
+    }
+}
+
+// publish вызывается внутри транзакции, которая держит соединение из пула и
+// блокировки строк outbox. Поэтому ожидание Kafka (вместе с повторами самого
+// writer'а) ограничено OUTBOX_PUBLISH_TIMEOUT: при недоступной Kafka попытка
+// быстро откатывается, а следующая будет после задержки Run
+func (r *OutboxRelay) publish(ctx context.Context, events []models.OutboxMessage) error {
+    ctx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+    defer cancel()
+
+    msgs := make([]kafka.Message, len(events))
+    for i, e := range events {
+        msgs[i] = outboxMessage(e)
+    }
+    return r.writer.WriteMessages(ctx, msgs...)
+}
+
+func outboxMessage(e models.OutboxMessage) kafka.Message {
+    return kafka.Message{
+        Key:   []byte(e.Key),
+        Value: e.Payload,
+        Time:  e.CreatedAt,
+        Headers: []kafka.Header{
+            {Key: headerEventType, Value: []byte(e.EventType)},
+            {Key: headerOutboxID, Value: []byte(strconv.FormatInt(e.ID, 10))},
+        },
+    }
+}
+
+func (r *OutboxRelay) cleanup(ctx context.Context) {
+    deleted, err := r.store.DeleteSentOutbox(ctx, time.Now().Add(-r.cfg.Retention))
+    if err != nil {
+        slog.Error("failed to delete sent outbox events", "error", err)
+        return
+    }
+    if deleted > 0 {
+        metrics.OutboxDeleted.Add(float64(deleted))
+        slog.Info("Deleted sent outbox events", "count", deleted)
+    }
+}
+
+// relayBackOff - задержки между попытками после ошибок, без ограничения по времени:
+// неопубликованные события остаются в БД и дождутся восстановления Kafka
+func relayBackOff() *backoff.ExponentialBackOff {
+    bo := backoff.NewExponentialBackOff()
+    bo.MaxElapsedTime = 0
+    bo.InitialInterval = 500 * time.Millisecond
+    bo.MaxInterval = 30 * time.Second
+    return bo
+}
+
+func (r *OutboxRelay) Close() {
+    if err := r.writer.Close(); err != nil {
+        slog.Error("failed to close outbox writer", "error", err)
+    }
+}
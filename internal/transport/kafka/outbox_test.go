@@ -0,0 +1,207 @@
+package kafka
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "L0/internal/config"
+    "L0/internal/models"
+
+    "github.com/segmentio/kafka-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/require"
+)
+
+// fakeOutbox хранит события в памяти так же, как таблица outbox:
+// событие отмечается отправленным, только если publish завершился без ошибки
+type fakeOutbox struct {
+    mu      sync.Mutex
+    events  []models.OutboxMessage
+    sent    map[int64]bool
+    deleted []time.Time
+}
+
+func newFakeOutbox(keys ...string) *fakeOutbox {
+    o := &fakeOutbox{sent: make(map[int64]bool)}
+    for i, key := range keys {
+        o.events = append(o.events, models.OutboxMessage{
+            ID:        int64(i + 1),
+            EventType: models.EventOrderStored,
+            Key:       key,
+            Payload:   []byte(`{"order_uid":"` + key + `"}`),
+        })
+    }
+    return o
+}
+
+func (o *fakeOutbox) PublishOutbox(ctx context.Context, limit int, publish func(context.Context, []models.OutboxMessage) error) (int, error) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+
+    var batch []models.OutboxMessage
+    for _, e := range o.events {
+        if !o.sent[e.ID] && len(batch) < limit {
+            batch = append(batch, e)
+        }
+    }
+    if len(batch) == 0 {
+        return 0, nil
+    }
+    if err := publish(ctx, batch); err != nil {
+        return 0, err
+    }
+    for _, e := range batch {
+        o.sent[e.ID] = true
+    }
+    return len(batch), nil
+}
+
+func (o *fakeOutbox) DeleteSentOutbox(_ context.Context, before time.Time) (int64, error) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    o.deleted = append(o.deleted, before)
+    return int64(len(o.sent)), nil
+}
+
+func (o *fakeOutbox) unsent() int {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    return len(o.events) - len(o.sent)
+}
+
+// flakyWriter отказывает первые failures раз, затем запоминает сообщения
+type flakyWriter struct {
+    mu       sync.Mutex
+    failures int
+    calls    int
+    written  []kafka.Message
+}
+
+func (w *flakyWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.calls++
+    if w.failures > 0 {
+        w.failures--
+        return errors.New("kafka is unavailable")
+    }
+    w.written = append(w.written, msgs...)
+    return nil
+}
+
+func (w *flakyWriter) messages() []kafka.Message {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return append([]kafka.Message(nil), w.written...)
+}
+
+func (w *flakyWriter) Close() error { return nil }
+
+func newTestRelay(store *fakeOutbox, writer messageWriter) *OutboxRelay {
+    return &OutboxRelay{
+        store:  store,
+        writer: writer,
+        cfg: config.Outbox{
+            Topic:           "orders-stored",
+            BatchSize:       2,
+            PollInterval:    10 * time.Millisecond,
+            PublishTimeout:  time.Second,
+            Retention:       time.Hour,
+            CleanupInterval: time.Hour,
+        },
+    }
+}
+
+func TestOutboxRelay_DrainPublishesAllBatches(t *testing.T) {
+    store := newFakeOutbox("order-1", "order-2", "order-3")
+    writer := &flakyWriter{}
+    relay := newTestRelay(store, writer)
+
+    require.NoError(t, relay.drain(context.Background()))
+
+    assert.Zero(t, store.unsent())
+    assert.Equal(t, 2, writer.calls, "3 events with batch size 2")
+
+    msgs := writer.messages()
+    require.Len(t, msgs, 3)
+    assert.Equal(t, "order-1", string(msgs[0].Key))
+    assert.JSONEq(t, `{"order_uid":"order-1"}`, string(msgs[0].Value))
+    assert.Equal(t, models.EventOrderStored, headerValue(msgs[0], headerEventType))
+    assert.Equal(t, "1", headerValue(msgs[0], headerOutboxID))
+    assert.Equal(t, "3", headerValue(msgs[2], headerOutboxID))
+}
+
+func TestOutboxRelay_FailedPublishLeavesEventsUnsent(t *testing.T) {
+    store := newFakeOutbox("order-1")
+    writer := &flakyWriter{failures: 1}
+    relay := newTestRelay(store, writer)
+
+    assert.Error(t, relay.drain(context.Background()))
+    assert.Equal(t, 1, store.unsent())
+    assert.Empty(t, writer.messages())
+
+    require.NoError(t, relay.drain(context.Background()))
+    assert.Zero(t, store.unsent())
+    assert.Len(t, writer.messages(), 1)
+}
+
+// blockingWriter ждёт, пока не отменят контекст, как writer при недоступной Kafka
+type blockingWriter struct{}
+
+func (blockingWriter) WriteMessages(ctx context.Context, _ ...kafka.Message) error {
+    <-ctx.Done()
+    return ctx.Err()
+}
+
+func (blockingWriter) Close() error { return nil }
+
+func TestOutboxRelay_PublishIsBoundedByTimeout(t *testing.T) {
+    store := newFakeOutbox("order-1")
+    relay := newTestRelay(store, blockingWriter{})
+    relay.cfg.PublishTimeout = 50 * time.Millisecond
+
+    start := time.Now()
+    err := relay.drain(context.Background())
+
+    assert.ErrorIs(t, err, context.DeadlineExceeded)
+    assert.Less(t, time.Since(start), time.Second, "locks are not held for the writer's retry budget")
+    assert.Equal(t, 1, store.unsent())
+}
+
+func TestOutboxRelay_RunRetriesUntilPublished(t *testing.T) {
+    store := newFakeOutbox("order-1", "order-2")
+    writer := &flakyWriter{failures: 2}
+    relay := newTestRelay(store, writer)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+    go func() {
+        relay.Run(ctx)
+        close(done)
+    }()
+
+    assert.Eventually(t, func() bool { return store.unsent() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+    cancel()
+    select {
+    case <-done:
+    case <-time.After(time.Second):
+        t.Fatal("relay did not stop after context cancellation")
+    }
+
+    assert.Len(t, writer.messages(), 2, "events are published once after the failed attempts")
+}
+
+func TestOutboxRelay_CleanupUsesRetention(t *testing.T) {
+    store := newFakeOutbox()
+    relay := newTestRelay(store, &flakyWriter{})
+
+    before := time.Now()
+    relay.cleanup(context.Background())
+
+    require.Len(t, store.deleted, 1)
+    assert.WithinDuration(t, before.Add(-time.Hour), store.deleted[0], time.Second)
+}
@@ -0,0 +1,162 @@
+package kafka
+
+import (
+    "context"
+    "hash/fnv"
+    "log/slog"
+    "strconv"
+    "sync"
+
+    "github.com/segmentio/kafka-go"
+)
+
+// runPool обрабатывает сообщения пулом воркеров.
+// Сообщения с одинаковыми партицией и ключом всегда попадают в один воркер и
+// обрабатываются по порядку, разные ключи обрабатываются параллельно.
+// Offset коммитится только после обработки всех предыдущих сообщений партиции.
+func (c *Consumer) runPool(ctx context.Context) {
+    workers := c.cfg.Kafka.Workers
+    slog.Info("Starting Kafka consumer in worker pool mode...", "workers", workers)
+
+    tracker := newOffsetTracker()
+    commits := make(chan kafka.Message, workers)
+
+    var committerWG sync.WaitGroup
+    committerWG.Add(1)
+    go func() {
+        defer committerWG.Done()
+        c.runCommitter(ctx, commits)
+    }()
+
+    queues := make([]chan kafka.Message, workers)
+    var workersWG sync.WaitGroup
+    for i := range queues {
+        queues[i] = make(chan kafka.Message, c.cfg.Kafka.QueueSize)
+        workersWG.Add(1)
+        go func(queue <-chan kafka.Message) {
+            defer workersWG.Done()
+            for m := range queue {
+                if err := c.process(ctx, m); err != nil {
+                    // Offset партиции дальше не сдвинется, сообщение перечитают после рестарта
+                    slog.Error("message left uncommitted", "error", err, "partition", m.Partition, "offset", m.Offset)
+                    continue
+                }
+                if last, ok := tracker.complete(m); ok {
+                    commits <- last
+                }
+            }
+        }(queues[i])
+    }
+
+    defer func() {
+        for _, queue := range queues {
+            close(queue)
+        }
+        workersWG.Wait()
+        close(commits)
+        committerWG.Wait()
+        slog.Info("Consumer worker pool stopped")
+    }()
+
+    for {
+        select {
+        case <-ctx.Done():
+            slog.Info("Consumer context cancelled, stopping...")
+            return
+        default:
+        }
+
+        m, err := c.fetch(ctx)
+        if err != nil {
+            slog.Error("failed to read message after retries", "error", err)
+            continue
+        }
+
+        tracker.add(m)
+
+        select {
+        case queues[workerFor(m, workers)] <- m:
+        case <-ctx.Done():
+            slog.Info("Consumer context cancelled, stopping...")
+            return
+        }
+    }
+}
+
+// runCommitter коммитит offset'ы из одной горутины, чтобы коммиты партиции
+// не обгоняли друг друга
+func (c *Consumer) runCommitter(ctx context.Context, commits <-chan kafka.Message) {
+    committed := make(map[int]int64)
+
+    for m := range commits {
+        if last, ok := committed[m.Partition]; ok && m.Offset <= last {
+            continue
+        }
+        c.commit(ctx, m)
+        committed[m.Partition] = m.Offset
+    }
+}
+
+// workerFor выбирает воркер по партиции и ключу сообщения.
+// Сообщения без ключа упорядочиваются в пределах партиции.
+func workerFor(m kafka.Message, workers int) int {
+    h := fnv.New32a()
+    _, _ = h.Write([]byte(strconv.Itoa(m.Partition)))
+    _, _ = h.Write(m.Key)
+    return int(h.Sum32() % uint32(workers))
+}
+
+// offsetTracker хранит незавершённые сообщения каждой партиции в порядке получения
+type offsetTracker struct {
+    mu         sync.Mutex
+    partitions map[int]*partitionOffsets
+}
+
+type partitionOffsets struct {
+    pending []kafka.Message
+    done    map[int64]struct{}
+}
+
+func newOffsetTracker() *offsetTracker {
+    return &offsetTracker{partitions: make(map[int]*partitionOffsets)}
+}
+
+func (t *offsetTracker) add(m kafka.Message) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    p, ok := t.partitions[m.Partition]
+    if !ok {
+        p = &partitionOffsets{done: make(map[int64]struct{})}
+        t.partitions[m.Partition] = p
+    }
+    p.pending = append(p.pending, m)
+}
+
+// complete отмечает сообщение обработанным и возвращает последнее сообщение
+// непрерывного обработанного префикса партиции, если префикс сдвинулся
+func (t *offsetTracker) complete(m kafka.Message) (kafka.Message, bool) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    p, ok := t.partitions[m.Partition]
+    if !ok {
+        return kafka.Message{}, false
+    }
+    p.done[m.Offset] = struct{}{}
+
+    var last kafka.Message
+    advanced := false
+    for len(p.pending) > 0 {
+        head := p.pending[0]
+        if _, isDone := p.done[head.Offset]; !isDone {
+            break
+        }
+        delete(p.done, head.Offset)
+        p.pending = p.pending[1:]
+        last = head
+        advanced = true
+    }
+
+    return last, advanced
+}
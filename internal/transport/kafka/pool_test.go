@@ -0,0 +1,151 @@
+package kafka
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "testing"
+    "time"
+
+    "L0/internal/models"
+
+    "github.com/segmentio/kafka-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+)
+
+func TestOffsetTracker_CommitsOnlyContiguousPrefix(t *testing.T) {
+    tracker := newOffsetTracker()
+    for offset := int64(0); offset < 3; offset++ {
+        tracker.add(kafka.Message{Partition: 0, Offset: offset})
+    }
+
+    // Более позднее сообщение завершилось раньше - коммитить нечего
+    _, ok := tracker.complete(kafka.Message{Partition: 0, Offset: 1})
+    assert.False(t, ok)
+
+    last, ok := tracker.complete(kafka.Message{Partition: 0, Offset: 0})
+    require.True(t, ok)
+    assert.Equal(t, int64(1), last.Offset)
+
+    last, ok = tracker.complete(kafka.Message{Partition: 0, Offset: 2})
+    require.True(t, ok)
+    assert.Equal(t, int64(2), last.Offset)
+}
+
+func TestOffsetTracker_PartitionsAreIndependent(t *testing.T) {
+    tracker := newOffsetTracker()
+    tracker.add(kafka.Message{Partition: 0, Offset: 10})
+    tracker.add(kafka.Message{Partition: 1, Offset: 5})
+
+    last, ok := tracker.complete(kafka.Message{Partition: 1, Offset: 5})
+    require.True(t, ok)
+    assert.Equal(t, 1, last.Partition)
+    assert.Equal(t, int64(5), last.Offset)
+}
+
+func TestWorkerFor_SameKeySameWorker(t *testing.T) {
+    a := kafka.Message{Partition: 3, Key: []byte("customer-1"), Offset: 1}
+    b := kafka.Message{Partition: 3, Key: []byte("customer-1"), Offset: 100}
+    assert.Equal(t, workerFor(a, 8), workerFor(b, 8))
+}
+
+func TestConsumer_RunPool_KeepsKeyOrderAndCommitsAll(t *testing.T) {
+    template, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "valid-order-template.json"))
+    require.NoError(t, err)
+
+    const partitions, keys, perKey = 3, 4, 5
+
+    var messages []kafka.Message
+    offsets := make(map[int]int64)
+    for i := 0; i < perKey; i++ {
+        for p := 0; p < partitions; p++ {
+            for k := 0; k < keys; k++ {
+                var order models.Order
+                require.NoError(t, json.Unmarshal(template, &order))
+                order.OrderUID = fmt.Sprintf("p%d-k%d-%d", p, k, i)
+                order.Payment.Transaction = order.OrderUID
+
+                value, err := json.Marshal(order)
+                require.NoError(t, err)
+
+                messages = append(messages, kafka.Message{
+                    Partition: p,
+                    Offset:    offsets[p],
+                    Key:       []byte(fmt.Sprintf("k%d", k)),
+                    Value:     value,
+                })
+                offsets[p]++
+            }
+        }
+    }
+
+    var mu sync.Mutex
+    seen := make(map[string][]string)
+
+    mockService := &MockOrderService{}
+    mockService.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+        order := args.Get(1).(models.Order)
+        time.Sleep(time.Millisecond)
+        mu.Lock()
+        key := order.OrderUID[:len("p0-k0")]
+        seen[key] = append(seen[key], order.OrderUID)
+        mu.Unlock()
+    }).Return(nil)
+
+    consumer, reader, _ := newTestConsumer(mockService)
+    consumer.cfg.Kafka.Workers = 4
+    consumer.cfg.Kafka.QueueSize = 2
+    reader.messages = messages
+
+    ctx, cancel := context.WithCancel(context.Background())
+    done := make(chan struct{})
+    go func() {
+        consumer.Run(ctx)
+        close(done)
+    }()
+
+    lastCommitted := func() map[int]int64 {
+        result := make(map[int]int64)
+        for _, m := range reader.committedMessages() {
+            result[m.Partition] = m.Offset
+        }
+        return result
+    }
+
+    assert.Eventually(t, func() bool {
+        committed := lastCommitted()
+        for p, next := range offsets {
+            if committed[p] != next-1 {
+                return false
+            }
+        }
+        return true
+    }, 5*time.Second, 10*time.Millisecond)
+
+    cancel()
+    <-done
+
+    // Коммиты в каждой партиции идут строго по возрастанию
+    prev := make(map[int]int64)
+    for _, m := range reader.committedMessages() {
+        if last, ok := prev[m.Partition]; ok {
+            assert.Greater(t, m.Offset, last)
+        }
+        prev[m.Partition] = m.Offset
+    }
+
+    // Заказы одного ключа сохранены в порядке поступления
+    mu.Lock()
+    defer mu.Unlock()
+    for key, uids := range seen {
+        require.Len(t, uids, perKey, key)
+        for i, uid := range uids {
+            assert.Equal(t, fmt.Sprintf("%s-%d", key, i), uid)
+        }
+    }
+}
@@ -0,0 +1,68 @@
+package kafka
+
+import (
+    "context"
+    "strconv"
+
+    "L0/internal/tracing"
+
+    "github.com/segmentio/kafka-go"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// Трейсер запрашивается у глобального провайдера на каждый span, а не один раз
+// при инициализации пакета: так в тестах провайдер можно подменить
+const tracerName = "L0/internal/transport/kafka"
+
+// startMessageSpan открывает span обработки сообщения, продолжая трассу
+// продюсера из заголовков сообщения
+func startMessageSpan(ctx context.Context, m kafka.Message) (context.Context, trace.Span) {
+    return otel.Tracer(tracerName).Start(tracing.ExtractKafka(ctx, m), "process "+m.Topic,
+        trace.WithSpanKind(trace.SpanKindConsumer),
+        trace.WithAttributes(messageAttributes(m)...),
+    )
+}
+
+// startBatchSpan открывает span обработки пачки. У сообщений пачки разные
+// трассы, поэтому они не родители span'а, а связаны с ним ссылками
+func startBatchSpan(ctx context.Context, topic string, msgs []kafka.Message) (context.Context, trace.Span) {
+    links := make([]trace.Link, 0, len(msgs))
+    for _, m := range msgs {
+        if sc := trace.SpanContextFromContext(tracing.ExtractKafka(ctx, m)); sc.IsValid() {
+            links = append(links, trace.Link{SpanContext: sc, Attributes: messageAttributes(m)})
+        }
+    }
+
+    return otel.Tracer(tracerName).Start(ctx, "process "+topic,
+        trace.WithSpanKind(trace.SpanKindConsumer),
+        trace.WithLinks(links...),
+        trace.WithAttributes(
+            semconv.MessagingSystemKafka,
+            semconv.MessagingOperationTypeProcess,
+            semconv.MessagingDestinationName(topic),
+            semconv.MessagingBatchMessageCount(len(msgs)),
+        ),
+    )
+}
+
+func messageAttributes(m kafka.Message) []attribute.KeyValue {
+    return []attribute.KeyValue{
+        semconv.MessagingSystemKafka,
+        semconv.MessagingOperationTypeProcess,
+        semconv.MessagingDestinationName(m.Topic),
+        semconv.MessagingDestinationPartitionID(strconv.Itoa(m.Partition)),
+        semconv.MessagingKafkaOffset(int(m.Offset)),
+        semconv.MessagingKafkaMessageKey(string(m.Key)),
+    }
+}
+
+// failSpan отмечает текущий span ошибкой: сообщение ушло в DLQ или не обработано
+func failSpan(ctx context.Context, err error) {
+    span := trace.SpanFromContext(ctx)
+    span.RecordError(err)
+    span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,78 @@
+package kafka
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "L0/internal/tracing"
+
+    "github.com/segmentio/kafka-go"
+    "github.com/stretchr/testify/assert"
+    "github.com/stretchr/testify/mock"
+    "github.com/stretchr/testify/require"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/propagation"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    "go.opentelemetry.io/otel/trace"
+)
+
+func newSpanRecorder(t *testing.T) (*tracetest.SpanRecorder, trace.Tracer) {
+    t.Helper()
+
+    recorder := tracetest.NewSpanRecorder()
+    provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+    prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+    otel.SetTracerProvider(provider)
+    otel.SetTextMapPropagator(propagation.TraceContext{})
+    t.Cleanup(func() {
+        otel.SetTracerProvider(prevProvider)
+        otel.SetTextMapPropagator(prevPropagator)
+    })
+    return recorder, provider.Tracer("producer")
+}
+
+func TestConsumer_Process_ContinuesProducerTrace(t *testing.T) {
+    recorder, producer := newSpanRecorder(t)
+
+    mockService := &MockOrderService{}
+    consumer, _, _ := newTestConsumer(mockService)
+
+    data, err := os.ReadFile(filepath.Join("..", "..", "..", "testdata", "valid-order-template.json"))
+    require.NoError(t, err)
+
+    var serviceSpan trace.SpanContext
+    mockService.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+        serviceSpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+    }).Return(nil).Once()
+
+    sendCtx, send := producer.Start(context.Background(), "send orders")
+    msg := kafka.Message{Topic: "orders", Partition: 1, Offset: 7, Value: data}
+    tracing.InjectKafka(sendCtx, &msg)
+    send.End()
+
+    require.NoError(t, consumer.process(context.Background(), msg))
+
+    spans := recorder.Ended()
+    require.Len(t, spans, 2)
+    process := spans[1]
+    assert.Equal(t, "process orders", process.Name())
+    assert.Equal(t, trace.SpanKindConsumer, process.SpanKind())
+    assert.Equal(t, send.SpanContext().TraceID(), process.SpanContext().TraceID())
+    assert.Equal(t, send.SpanContext().SpanID(), process.Parent().SpanID())
+    assert.Equal(t, process.SpanContext().SpanID(), serviceSpan.SpanID(), "service is called within the message span")
+}
+
+func TestConsumer_Process_DLQMarksSpanFailed(t *testing.T) {
+    recorder, _ := newSpanRecorder(t)
+
+    consumer, _, _ := newTestConsumer(&MockOrderService{})
+    require.NoError(t, consumer.process(context.Background(), kafka.Message{Topic: "orders", Value: []byte(`{"invalid": json}`)}))
+
+    spans := recorder.Ended()
+    require.Len(t, spans, 1)
+    assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
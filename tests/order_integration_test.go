@@ -2,10 +2,15 @@ package tests
 
 import (
     "L0/internal/config"
+    "L0/internal/migrations"
     "L0/internal/models"
+    "L0/internal/pii"
+    "L0/internal/repository"
     repoPostgres "L0/internal/repository/postgres"
+    "L0/internal/repository/repotest"
+    "bytes"
+    "encoding/json"
     "context"
-    "os"
     "testing"
     "time"
 
@@ -41,9 +46,9 @@ func setupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
     pool, err := pgxpool.New(ctx, connStr)
     require.NoError(t, err)
 
-    schema, err := os.ReadFile("../init.sql")
+    migrator, err := migrations.NewPostgres(pool)
     require.NoError(t, err)
-    _, err = pool.Exec(ctx, string(schema))
+    _, err = migrator.Up(ctx)
     require.NoError(t, err)
 
     cleanup := func() {
@@ -140,4 +145,357 @@ func TestRepository_Integration_Create(t *testing.T) {
         assert.Equal(t, order.Items[0].Name, items[0].Name)
         assert.Equal(t, order.Items[1].Brand, items[1].Brand)
     })
-}
\ No newline at end of file
+}
+
+func TestRepository_Integration_List(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    repo := repoPostgres.New(pool, &config.Config{
+        Retry: config.Retry{
+            MaxElapsedTimeDB:   5 * time.Second,
+            MaxElapsedTimeRead: 3 * time.Second,
+            InitialInterval:    100 * time.Millisecond,
+            MaxIntervalDB:      1 * time.Second,
+            MaxIntervalRead:    500 * time.Millisecond,
+        },
+    })
+
+    ctx := context.Background()
+    base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+    newOrder := func(uid, customer, currency string, created time.Time) models.Order {
+        return models.Order{
+            OrderUID:    uid,
+            TrackNumber: "TRACK-" + uid,
+            CustomerID:  customer,
+            DateCreated: created,
+            Delivery:    models.Delivery{Name: "Test User", City: "Testville"},
+            Payment:     models.Payment{Transaction: uid, Currency: currency, Amount: 100},
+            Items: []models.Item{
+                {ChrtID: 1, TrackNumber: "TRACK-" + uid, Price: 100, Name: "Item"},
+            },
+        }
+    }
+
+    orders := []models.Order{
+        newOrder("list-1", "alice", "USD", base),
+        newOrder("list-2", "bob", "EUR", base.Add(time.Hour)),
+        newOrder("list-3", "alice", "USD", base.Add(2*time.Hour)),
+        newOrder("list-4", "alice", "EUR", base.Add(3*time.Hour)),
+    }
+    for _, o := range orders {
+        require.NoError(t, repo.Create(ctx, o))
+    }
+
+    t.Run("pagination", func(t *testing.T) {
+        var uids []string
+        filter := models.OrderFilter{Limit: 3}
+        for {
+            page, err := repo.List(ctx, filter)
+            require.NoError(t, err)
+            for _, o := range page.Orders {
+                uids = append(uids, o.OrderUID)
+                // Связанные данные подгружены для каждого заказа страницы
+                assert.Equal(t, o.OrderUID, o.Payment.Transaction)
+                assert.Len(t, o.Items, 1)
+            }
+            if page.NextCursor == nil {
+                break
+            }
+            filter.Cursor = page.NextCursor
+        }
+        assert.Equal(t, []string{"list-4", "list-3", "list-2", "list-1"}, uids)
+    })
+
+    t.Run("filters", func(t *testing.T) {
+        page, err := repo.List(ctx, models.OrderFilter{
+            CustomerID:  "alice",
+            Currency:    "USD",
+            CreatedFrom: base.Add(time.Minute),
+            Limit:       10,
+        })
+        require.NoError(t, err)
+        require.Len(t, page.Orders, 1)
+        assert.Equal(t, "list-3", page.Orders[0].OrderUID)
+        assert.Nil(t, page.NextCursor)
+    })
+}
+
+func TestRepository_Integration_TypedErrors(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    repo := repoPostgres.New(pool, &config.Config{
+        Retry: config.Retry{
+            MaxElapsedTimeDB:   5 * time.Second,
+            MaxElapsedTimeRead: 3 * time.Second,
+            InitialInterval:    100 * time.Millisecond,
+            MaxIntervalDB:      1 * time.Second,
+            MaxIntervalRead:    500 * time.Millisecond,
+        },
+    })
+
+    ctx := context.Background()
+
+    _, err := repo.GetByUID(ctx, "missing-uid")
+    assert.Equal(t, models.OrderNotFoundError{OrderUID: "missing-uid"}, err)
+
+    order := models.Order{
+        OrderUID:    "duplicate-uid",
+        TrackNumber: "TRACK123",
+        CustomerID:  "customer-1",
+        DateCreated: time.Now().UTC(),
+        Payment:     models.Payment{Transaction: "duplicate-uid"},
+    }
+    require.NoError(t, repo.Create(ctx, order))
+
+    err = repo.Create(ctx, order)
+    assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "duplicate-uid"}, err)
+}
+
+func TestRepository_Integration_CreateBatch(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    repo := repoPostgres.New(pool, &config.Config{
+        Retry: config.Retry{
+            MaxElapsedTimeDB:   5 * time.Second,
+            MaxElapsedTimeRead: 3 * time.Second,
+            InitialInterval:    100 * time.Millisecond,
+            MaxIntervalDB:      1 * time.Second,
+            MaxIntervalRead:    500 * time.Millisecond,
+        },
+    })
+
+    ctx := context.Background()
+
+    newOrder := func(uid string) models.Order {
+        return models.Order{
+            OrderUID:    uid,
+            TrackNumber: "TRACK-" + uid,
+            CustomerID:  "customer-1",
+            DateCreated: time.Now().UTC(),
+            Delivery:    models.Delivery{Name: "Test User", City: "Testville"},
+            Payment:     models.Payment{Transaction: uid, Currency: "USD", Amount: 100},
+            Items: []models.Item{
+                {ChrtID: 1, TrackNumber: "TRACK-" + uid, Price: 100, Name: "Item 1"},
+                {ChrtID: 2, TrackNumber: "TRACK-" + uid, Price: 200, Name: "Item 2"},
+            },
+        }
+    }
+
+    require.NoError(t, repo.Create(ctx, newOrder("batch-existing")))
+
+    results, err := repo.CreateBatch(ctx, []models.Order{
+        newOrder("batch-1"),
+        newOrder("batch-existing"),
+        newOrder("batch-2"),
+    })
+    require.NoError(t, err)
+    require.Len(t, results, 3)
+    assert.NoError(t, results[0])
+    assert.Equal(t, models.OrderAlreadyExistsError{OrderUID: "batch-existing"}, results[1])
+    assert.NoError(t, results[2])
+
+    for _, uid := range []string{"batch-1", "batch-2"} {
+        stored, err := repo.GetByUID(ctx, uid)
+        require.NoError(t, err)
+        assert.Equal(t, uid, stored.Payment.Transaction)
+        assert.Len(t, stored.Items, 2)
+    }
+}
+
+func TestRepository_Integration_Conformance(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    repotest.Run(t, func(t *testing.T) repository.OrderRepository {
+        _, err := pool.Exec(context.Background(), "TRUNCATE orders CASCADE")
+        require.NoError(t, err)
+
+        return repoPostgres.New(pool, &config.Config{
+            Retry: config.Retry{
+                MaxElapsedTimeDB:   5 * time.Second,
+                MaxElapsedTimeRead: 3 * time.Second,
+                InitialInterval:    100 * time.Millisecond,
+                MaxIntervalDB:      1 * time.Second,
+                MaxIntervalRead:    500 * time.Millisecond,
+            },
+        })
+    })
+}
+
+func TestMigrations_Integration_UpDown(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    ctx := context.Background()
+    migrator, err := migrations.NewPostgres(pool)
+    require.NoError(t, err)
+
+    // Повторный up ничего не применяет
+    applied, err := migrator.Up(ctx)
+    require.NoError(t, err)
+    assert.Equal(t, 0, applied)
+
+    statuses, err := migrator.Status(ctx)
+    require.NoError(t, err)
+    for _, s := range statuses {
+        assert.True(t, s.Applied, s.Name)
+    }
+
+    reverted, err := migrator.Down(ctx, len(statuses))
+    require.NoError(t, err)
+    assert.Equal(t, len(statuses), reverted)
+
+    var exists bool
+    err = pool.QueryRow(ctx, "SELECT to_regclass('orders') IS NOT NULL").Scan(&exists)
+    require.NoError(t, err)
+    assert.False(t, exists)
+}
+
+func TestRepository_Integration_GetAPIKey(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    ctx := context.Background()
+    repo := repoPostgres.New(pool, &config.Config{})
+
+    _, err := pool.Exec(ctx, `INSERT INTO api_keys (key_hash, name, role, revoked_at) VALUES ($1, 'team', 'support', NULL), ($2, 'old', 'admin', NOW())`,
+        models.HashAPIKey("active"), models.HashAPIKey("revoked"))
+    require.NoError(t, err)
+
+    key, err := repo.GetAPIKey(ctx, models.HashAPIKey("active"))
+    require.NoError(t, err)
+    assert.Equal(t, models.APIKey{Name: "team", Role: models.RoleSupport}, key)
+
+    _, err = repo.GetAPIKey(ctx, models.HashAPIKey("revoked"))
+    assert.ErrorIs(t, err, models.ErrAPIKeyNotFound)
+}
+
+func TestRepository_Integration_PIIEncryption(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    ctx := context.Background()
+    oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+
+    oldRing, err := pii.NewKeyring("k1", map[string][]byte{"k1": oldKey})
+    require.NoError(t, err)
+    plainRepo := repoPostgres.New(pool, &config.Config{})
+    oldRepo := repoPostgres.New(pool, &config.Config{}).WithKeyring(oldRing)
+
+    encrypted := repotest.NewOrder("encrypted-order", "customer", time.Now().UTC())
+    legacy := repotest.NewOrder("legacy-order", "customer", time.Now().UTC())
+    require.NoError(t, oldRepo.Create(ctx, encrypted))
+    require.NoError(t, plainRepo.Create(ctx, legacy))
+
+    var phone string
+    var keyID *string
+    require.NoError(t, pool.QueryRow(ctx, `SELECT phone, key_id FROM deliveries WHERE order_uid = $1`, encrypted.OrderUID).Scan(&phone, &keyID))
+    assert.True(t, pii.IsEncrypted(phone))
+    require.NotNil(t, keyID)
+    assert.Equal(t, "k1", *keyID)
+
+    stored, err := oldRepo.GetByUID(ctx, encrypted.OrderUID)
+    require.NoError(t, err)
+    assert.Equal(t, encrypted.Delivery, stored.Delivery)
+
+    // Ротация: новый основной ключ, старый пока остаётся в файле
+    rotatedRing, err := pii.NewKeyring("k2", map[string][]byte{"k1": oldKey, "k2": newKey})
+    require.NoError(t, err)
+    rotated, err := repoPostgres.New(pool, &config.Config{}).WithKeyring(rotatedRing).RewrapPII(ctx, 1)
+    require.NoError(t, err)
+    assert.Equal(t, 2, rotated, "both the old-key row and the plaintext row are rewritten")
+
+    newRing, err := pii.NewKeyring("k2", map[string][]byte{"k2": newKey})
+    require.NoError(t, err)
+    newRepo := repoPostgres.New(pool, &config.Config{}).WithKeyring(newRing)
+
+    for _, want := range []models.Order{encrypted, legacy} {
+        require.NoError(t, pool.QueryRow(ctx, `SELECT phone, key_id FROM deliveries WHERE order_uid = $1`, want.OrderUID).Scan(&phone, &keyID))
+        assert.True(t, pii.IsEncrypted(phone))
+        require.NotNil(t, keyID)
+        assert.Equal(t, "k2", *keyID)
+
+        stored, err := newRepo.GetByUID(ctx, want.OrderUID)
+        require.NoError(t, err)
+        assert.Equal(t, want.Delivery, stored.Delivery)
+    }
+
+    rotated, err = newRepo.RewrapPII(ctx, 10)
+    require.NoError(t, err)
+    assert.Zero(t, rotated)
+}
+
+func TestRepository_Integration_Outbox(t *testing.T) {
+    pool, cleanup := setupTestDB(t)
+    defer cleanup()
+
+    ctx := context.Background()
+    repo := repoPostgres.New(pool, &config.Config{
+        Outbox: config.Outbox{Enabled: true},
+        Retry: config.Retry{
+            MaxElapsedTimeDB: time.Second,
+            InitialInterval:  100 * time.Millisecond,
+            MaxIntervalDB:    500 * time.Millisecond,
+        },
+    })
+
+    order := repotest.NewOrder("outbox-order", "customer", time.Now().UTC())
+    require.NoError(t, repo.Create(ctx, order))
+
+    // Дубликат откатывается целиком, второго события нет
+    err := repo.Create(ctx, order)
+    assert.ErrorAs(t, err, &models.OrderAlreadyExistsError{})
+
+    results, err := repo.CreateBatch(ctx, []models.Order{
+        repotest.NewOrder("outbox-batch", "customer", time.Now().UTC()),
+        order,
+    })
+    require.NoError(t, err)
+    assert.NoError(t, results[0])
+    assert.Error(t, results[1])
+
+    // Ошибка публикации оставляет события неотправленными
+    _, err = repo.PublishOutbox(ctx, 10, func(context.Context, []models.OutboxMessage) error {
+        return assert.AnError
+    })
+    assert.Error(t, err)
+
+    var published []models.OutboxMessage
+    n, err := repo.PublishOutbox(ctx, 10, func(_ context.Context, msgs []models.OutboxMessage) error {
+        published = append(published, msgs...)
+        return nil
+    })
+    require.NoError(t, err)
+    assert.Equal(t, 2, n)
+    require.Len(t, published, 2)
+    assert.Equal(t, "outbox-order", published[0].Key)
+    assert.Equal(t, "outbox-batch", published[1].Key)
+    assert.Equal(t, models.EventOrderStored, published[0].EventType)
+    assert.JSONEq(t, `"outbox-order"`, string(mustField(t, published[0].Payload, "order_uid")))
+
+    n, err = repo.PublishOutbox(ctx, 10, func(context.Context, []models.OutboxMessage) error {
+        t.Fatal("sent events must not be published again")
+        return nil
+    })
+    require.NoError(t, err)
+    assert.Zero(t, n)
+
+    deleted, err := repo.DeleteSentOutbox(ctx, time.Now().Add(-time.Hour))
+    require.NoError(t, err)
+    assert.Zero(t, deleted, "events sent recently are kept")
+
+    deleted, err = repo.DeleteSentOutbox(ctx, time.Now().Add(time.Minute))
+    require.NoError(t, err)
+    assert.Equal(t, int64(2), deleted)
+}
+
+func mustField(t *testing.T, payload []byte, field string) json.RawMessage {
+    t.Helper()
+    var fields map[string]json.RawMessage
+    require.NoError(t, json.Unmarshal(payload, &fields))
+    return fields[field]
+}